@@ -0,0 +1,319 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	applyCmdUse = "apply <update_loc> <carbon_home>"
+	applyCmdShortDesc = "Apply an update to a distribution"
+	applyCmdLongDesc = dedent.Dedent(`
+		This command will apply the given update zip to the extracted
+		distribution at the given location. Files in the update will be
+		copied to the distribution and removed files will be deleted. The
+		applied update will be recorded in the distribution so that it can
+		be used to check prerequisites of the updates and to detect files
+		which were changed after applying updates.
+
+		The detached signature of the update zip is verified using the
+		public key given with --verify or configured in the config file.
+		Updates without a valid signature are refused unless --skip-verify
+		is given.`)
+)
+
+// Whether the signature verification should be skipped when applying updates.
+var isSignatureVerificationSkipped bool
+
+// applyCmd represents the apply command.
+var applyCmd = &cobra.Command{
+	Use: applyCmdUse,
+	Short: applyCmdShortDesc,
+	Long: applyCmdLongDesc,
+	Run: initializeApplyCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(applyCmd)
+
+	applyCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+	applyCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+
+	applyCmd.Flags().StringVar(&verificationKeyPath, "verify", "", "Public key used to verify the signature of the update")
+	applyCmd.Flags().BoolVar(&isSignatureVerificationSkipped, "skip-verify", false, "Apply the update without verifying the signature")
+}
+
+// This function will be called when the apply command is called.
+func initializeApplyCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc apply --help' to view help."))
+	}
+	applyUpdate(args[0], args[1])
+}
+
+// This function will apply the given update to the given distribution.
+func applyUpdate(updateFilePath, carbonHome string) {
+	setLogLevel()
+	logger.Debug("[apply] command called")
+
+	//1) Check whether the update file exists and has a valid name
+	exists, err := util.IsFileExists(updateFilePath)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while checking '%s'", updateFilePath))
+	if !exists {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Entered update file does not exist at '%s'.", updateFilePath)))
+	}
+	updateFileName := filepath.Base(updateFilePath)
+	match, err := regexp.MatchString(constant.FILENAME_REGEX, updateFileName)
+	util.HandleErrorAndExit(err)
+	if !match {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Update filename '%s' does not match '%s' regular expression.", updateFileName, constant.FILENAME_REGEX)))
+	}
+	updateName := strings.TrimSuffix(updateFileName, ".zip")
+
+	//2) Verify the signature of the update
+	publicKeyPath := getVerificationKeyPath()
+	if isSignatureVerificationSkipped {
+		util.PrintWarning(fmt.Sprintf("Signature verification of '%s' skipped.", updateName))
+	} else if len(publicKeyPath) == 0 {
+		util.HandleErrorAndExit(errors.New("Public key is required to verify the signature of the update. Use --verify to give the public key or --skip-verify to apply the update without verifying the signature."))
+	} else {
+		err = util.VerifyFileSignature(updateFilePath, publicKeyPath)
+		util.HandleErrorAndExit(err, fmt.Sprintf("Cannot apply '%s'.", updateName))
+		util.PrintInfo(fmt.Sprintf("Signature of '%s' successfully verified.", updateName))
+	}
+
+	//3) Check whether the distribution exists
+	exists, err = util.IsDirectoryExists(carbonHome)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while checking '%s'", carbonHome))
+	if !exists {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Directory does not exist at '%s'. Distribution must be an extracted directory.", carbonHome)))
+	}
+
+	//4) Read and validate the update-descriptor.yaml
+	updateDescriptor, err := util.ReadUpdateDescriptorFromZip(updateFilePath)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+	err = util.ValidateUpdateDescriptor(updateDescriptor)
+	util.HandleErrorAndExit(err, fmt.Sprintf("'%s' format is incorrect.", constant.UPDATE_DESCRIPTOR_FILE))
+
+	//5) Check the ledger of the distribution
+	ledger, err := util.LoadLedger(carbonHome)
+	util.HandleErrorAndExit(err, "Error occurred while reading the applied updates.")
+	if util.IsUpdateApplied(ledger, updateName) {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("'%s' is already applied to '%s'.", updateName, carbonHome)))
+	}
+	err = util.CheckPrerequisites(ledger, updateDescriptor)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Cannot apply '%s'.", updateName))
+
+	//6) Copy the files in the update to the distribution and delete the removed files
+	appliedFiles, backups, backupDirectory, err := applyUpdateFiles(updateFilePath, updateName, carbonHome,
+		updateDescriptor.File_changes.Removed_files)
+	util.HandleErrorAndExit(err, "Error occurred while applying the update.")
+
+	//7) Record the applied update
+	ledger.AppliedUpdates = append(ledger.AppliedUpdates, util.AppliedUpdate{
+		UpdateName: updateName,
+		UpdateNumber: updateDescriptor.Update_number,
+		PlatformVersion: updateDescriptor.Platform_version,
+		AppliedTime: time.Now().UTC().Format(time.RFC3339),
+		ToolVersion: Version,
+		Files: appliedFiles,
+	})
+	err = util.SaveLedger(carbonHome, ledger)
+	if err != nil {
+		// The update is not recorded, so the changed files are restored to keep the distribution and the ledger in sync
+		restoreBackups(backups)
+		util.CleanUpDirectory(backupDirectory)
+		util.HandleErrorAndExit(err, "Error occurred while saving the applied updates. Changed files are restored.")
+	}
+	util.CleanUpDirectory(backupDirectory)
+
+	util.PrintInfo(fmt.Sprintf("'%s' successfully applied to '%s'.", updateName, carbonHome))
+}
+
+// struct which is used to store a change which will be done to a file in the distribution
+type fileChange struct {
+	// Path of the file relative to the distribution
+	relativePath string
+	// Location of the file in the distribution
+	destination  string
+	// Entry of the update zip which is copied to the destination. If this is nil, the destination is removed.
+	file         *zip.File
+}
+
+// This function will copy all files in the carbon.home directory of the update zip to the distribution, delete the
+// given removed files and return the md5 hashes of the files before and after the changes. All paths are checked before
+// changing any file, so an update which has a path outside the distribution is refused. Files which are overwritten or
+// removed are backed up first. If a change fails, the distribution is restored using the backups. Otherwise the backups
+// and the backup directory are returned so that the caller can restore the distribution if a later step fails. The
+// caller should delete the backup directory.
+func applyUpdateFiles(updateFilePath, updateName, carbonHome string, removedFiles []string) ([]util.AppliedFile, map[string]string, string, error) {
+	zipReader, err := zip.OpenReader(updateFilePath)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	defer zipReader.Close()
+
+	changes, err := getFileChanges(zipReader, updateName, carbonHome, removedFiles)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	backupDirectory, err := ioutil.TempDir("", constant.TEMP_DIR_PREFIX)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	appliedFiles := make([]util.AppliedFile, 0)
+	// Locations of the changed files mapped to the locations of their backups. Empty backup location means that the
+	// file did not exist before applying the update.
+	backups := make(map[string]string)
+	for index, change := range changes {
+		appliedFile, err := applyFileChange(change, filepath.Join(backupDirectory, strconv.Itoa(index)), backups)
+		if err != nil {
+			restoreBackups(backups)
+			util.CleanUpDirectory(backupDirectory)
+			return nil, nil, "", errors.New(fmt.Sprintf("Error occurred while changing '%s'. Changed files are restored. %v",
+				change.relativePath, err))
+		}
+		if appliedFile != nil {
+			appliedFiles = append(appliedFiles, *appliedFile)
+		}
+	}
+	return appliedFiles, backups, backupDirectory, nil
+}
+
+// This function will return the changes which should be done to the distribution to apply the given update. An error
+// is returned if any of the files is outside the distribution.
+func getFileChanges(zipReader *zip.ReadCloser, updateName, carbonHome string, removedFiles []string) ([]fileChange, error) {
+	changes := make([]fileChange, 0)
+	prefix := path.Join(updateName, constant.CARBON_HOME) + "/"
+	for _, file := range zipReader.Reader.File {
+		fileName := filepath.ToSlash(file.Name)
+		if file.FileInfo().IsDir() || !strings.HasPrefix(fileName, prefix) {
+			continue
+		}
+		relativePath := strings.TrimPrefix(fileName, prefix)
+		destination, err := util.GetPathInDirectory(carbonHome, relativePath)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Update contains an invalid file '%s'. %v", fileName, err))
+		}
+		changes = append(changes, fileChange{relativePath: relativePath, destination: destination, file: file})
+	}
+	for _, removedFile := range removedFiles {
+		destination, err := util.GetPathInDirectory(carbonHome, removedFile)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("'%s' contains an invalid removed file '%s'. %v",
+				constant.UPDATE_DESCRIPTOR_FILE, removedFile, err))
+		}
+		changes = append(changes, fileChange{relativePath: removedFile, destination: destination})
+	}
+	return changes, nil
+}
+
+// This function will back up the destination of the given change to the given location and apply the change.
+func applyFileChange(change fileChange, backupLocation string, backups map[string]string) (*util.AppliedFile, error) {
+	md5Before, err := util.GetMD5IfExists(change.destination)
+	if err != nil {
+		return nil, err
+	}
+	if _, found := backups[change.destination]; !found {
+		if len(md5Before) > 0 {
+			err = util.CopyFile(change.destination, backupLocation)
+			if err != nil {
+				return nil, err
+			}
+			backups[change.destination] = backupLocation
+		} else {
+			backups[change.destination] = ""
+		}
+	}
+
+	if change.file == nil {
+		logger.Debug(fmt.Sprintf("[REMOVE] %s", change.destination))
+		if len(md5Before) == 0 {
+			util.PrintWarning(fmt.Sprintf("Removed file '%s' not found in the distribution.", change.relativePath))
+		} else if err = os.Remove(change.destination); err != nil {
+			return nil, err
+		}
+		return &util.AppliedFile{
+			Path: change.relativePath,
+			Md5Before: md5Before,
+		}, nil
+	}
+
+	logger.Debug(fmt.Sprintf("[APPLY] %s -> %s", change.file.Name, change.destination))
+	err = extractFile(change.file, change.destination)
+	if err != nil {
+		return nil, err
+	}
+	md5After, err := util.GetMD5(change.destination)
+	if err != nil {
+		return nil, err
+	}
+	return &util.AppliedFile{
+		Path: change.relativePath,
+		Md5Before: md5Before,
+		Md5After: md5After,
+	}, nil
+}
+
+// This function will restore the files in the distribution using the given backups. Files which did not exist before
+// applying the update are removed.
+func restoreBackups(backups map[string]string) {
+	for destination, backupLocation := range backups {
+		var err error
+		if len(backupLocation) == 0 {
+			err = os.Remove(destination)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		} else {
+			err = util.CopyFile(backupLocation, destination)
+		}
+		if err != nil {
+			util.PrintWarning(fmt.Sprintf("Error occurred while restoring '%s'. %v", destination, err))
+		}
+	}
+}
+
+// This function will extract the given zip entry to the given destination.
+func extractFile(file *zip.File, destination string) error {
+	err := util.CreateDirectory(filepath.Dir(destination))
+	if err != nil {
+		return err
+	}
+	zippedFile, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer zippedFile.Close()
+	destinationFile, err := os.OpenFile(destination, os.O_WRONLY | os.O_TRUNC | os.O_CREATE, file.Mode())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destinationFile, zippedFile)
+	if err != nil {
+		destinationFile.Close()
+		return err
+	}
+	return destinationFile.Close()
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// This function will write a zip which contains the given files.
+func writeApplyTestZip(t *testing.T, zipPath string, files map[string]string) {
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zipWriter := zip.NewWriter(zipFile)
+	for name, content := range files {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte(content))
+	}
+	if err = zipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = zipFile.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestApplyUpdateFilesOutsideDistribution(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	carbonHome := filepath.Join(root, "a", "wso2esb-4.9.0")
+	if err = os.MkdirAll(filepath.Join(carbonHome, "bin"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(carbonHome, "bin", "a.sh"), []byte("old"), 0600)
+	updateName := "WSO2-CARBON-UPDATE-4.4.0-0001"
+	updatePath := filepath.Join(root, updateName + ".zip")
+
+	// Zip entry outside the distribution
+	writeApplyTestZip(t, updatePath, map[string]string{
+		updateName + "/carbon.home/bin/a.sh": "new",
+		updateName + "/carbon.home/../../x.txt": "x",
+	})
+	_, _, _, err = applyUpdateFiles(updatePath, updateName, carbonHome, nil)
+	if err == nil {
+		t.Error("Test failed. Error expected for a file outside the distribution")
+	}
+	if _, err := os.Stat(filepath.Join(root, "x.txt")); !os.IsNotExist(err) {
+		t.Error("Test failed. File outside the distribution should not be written")
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(carbonHome, "bin", "a.sh")); string(data) != "old" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "old", string(data))
+	}
+
+	// Removed file outside the distribution
+	ioutil.WriteFile(filepath.Join(root, "y.txt"), []byte("y"), 0600)
+	writeApplyTestZip(t, updatePath, map[string]string{
+		updateName + "/carbon.home/bin/a.sh": "new",
+	})
+	_, _, _, err = applyUpdateFiles(updatePath, updateName, carbonHome, []string{"../../y.txt"})
+	if err == nil {
+		t.Error("Test failed. Error expected for a removed file outside the distribution")
+	}
+	if _, err := os.Stat(filepath.Join(root, "y.txt")); err != nil {
+		t.Error("Test failed. File outside the distribution should not be removed")
+	}
+
+	// Valid update
+	appliedFiles, backups, backupDirectory, err := applyUpdateFiles(updatePath, updateName, carbonHome, []string{"bin/b.sh"})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(backupDirectory)
+	if len(appliedFiles) != 2 || appliedFiles[0].Path != "bin/a.sh" || len(appliedFiles[0].Md5Before) == 0 {
+		t.Errorf("Test failed, unexpected applied files: %v", appliedFiles)
+	}
+	if data, _ := ioutil.ReadFile(filepath.Join(carbonHome, "bin", "a.sh")); string(data) != "new" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "new", string(data))
+	}
+
+	// Backups are kept so that the distribution can be restored if the ledger cannot be saved
+	restoreBackups(backups)
+	if data, _ := ioutil.ReadFile(filepath.Join(carbonHome, "bin", "a.sh")); string(data) != "old" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "old", string(data))
+	}
+}
+
+func TestRestoreBackups(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	existingFile := filepath.Join(root, "a.sh")
+	newFile := filepath.Join(root, "b.sh")
+	ioutil.WriteFile(existingFile, []byte("old"), 0600)
+
+	backups := make(map[string]string)
+	_, err = applyFileChange(fileChange{relativePath: "a.sh", destination: existingFile}, filepath.Join(root, "backup"), backups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(newFile, []byte("new"), 0600)
+	backups[newFile] = ""
+
+	restoreBackups(backups)
+	if data, _ := ioutil.ReadFile(existingFile); string(data) != "old" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "old", string(data))
+	}
+	if _, err := os.Stat(newFile); !os.IsNotExist(err) {
+		t.Error("Test failed. New file should be removed")
+	}
+}
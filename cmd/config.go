@@ -0,0 +1,356 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Values used to print help command.
+var (
+	configCmdUse = "config"
+	configCmdShortDesc = "Show and validate the configuration"
+	configCmdLongDesc = dedent.Dedent(`
+		This command contains sub commands which are used to view and
+		validate the configuration. The config file is set using the
+		'--config' flag. If it is not set, 'config.yaml' in the current
+		directory or in '$HOME/.wum-uc' is used. A profile in the
+		'profiles' section of the config file can be selected using the
+		'--profile' flag.`)
+
+	configShowCmdUse = "show"
+	configShowCmdShortDesc = "Show the effective configuration"
+	configShowCmdLongDesc = dedent.Dedent(`
+		This command will print the value of each config after merging the
+		defaults, the config file, the selected profile and the environment
+		variables, along with the source of the value.`)
+
+	configValidateCmdUse = "validate"
+	configValidateCmdShortDesc = "Validate the config file"
+	configValidateCmdLongDesc = dedent.Dedent(`
+		This command will check the types and the values of the configs in
+		the config file and in each of its profiles.`)
+)
+
+// Configs which are shown by the config show command, in the order they are shown.
+var configKeys = []string{
+	constant.CHECK_MD5_DISABLED,
+	constant.KNOWN_UPDATES_DIRECTORY,
+	constant.UPDATE_REGISTRY,
+	constant.OUTPUT_LOCATION,
+	constant.SIGNING_KEY,
+	constant.VERIFICATION_KEY,
+	constant.RESOURCE_FILES_MANDATORY,
+	constant.RESOURCE_FILES_OPTIONAL,
+	constant.RESOURCE_FILES_SKIP,
+	constant.RESOURCE_FILES_PRODUCTS,
+	constant.PLATFORM_VERSIONS,
+	constant.PRODUCT_CATALOG,
+}
+
+// configCmd represents the config command.
+var configCmd = &cobra.Command{
+	Use: configCmdUse,
+	Short: configCmdShortDesc,
+	Long: configCmdLongDesc,
+}
+
+// configShowCmd represents the config show command.
+var configShowCmd = &cobra.Command{
+	Use: configShowCmdUse,
+	Short: configShowCmdShortDesc,
+	Long: configShowCmdLongDesc,
+	Run: initializeConfigShowCommand,
+}
+
+// configValidateCmd represents the config validate command.
+var configValidateCmd = &cobra.Command{
+	Use: configValidateCmdUse,
+	Short: configValidateCmdShortDesc,
+	Long: configValidateCmdLongDesc,
+	Run: initializeConfigValidateCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(configCmd)
+
+	for _, command := range []*cobra.Command{configShowCmd, configValidateCmd} {
+		configCmd.AddCommand(command)
+		command.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+		command.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+	}
+}
+
+// This function will be called when the config show command is called.
+func initializeConfigShowCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc config show --help' to view help."))
+	}
+	setLogLevel()
+	logger.Debug("[config show] command called")
+
+	if fileConfig != nil {
+		util.PrintInfo(fmt.Sprintf("Config file: %s", fileConfig.ConfigFileUsed()))
+	} else {
+		util.PrintInfo("Config file not found. Default values are used.")
+	}
+	if profileConfig != nil {
+		util.PrintInfo(fmt.Sprintf("Profile: %s", viper.GetString(constant.PROFILE)))
+	}
+
+	configTable := tablewriter.NewWriter(os.Stdout)
+	configTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	configTable.SetAutoWrapText(false)
+	configTable.SetHeader([]string{"Key", "Value", "Source"})
+	for _, key := range configKeys {
+		configTable.Append([]string{strings.ToLower(key), formatConfigValue(getEffectiveConfigValue(key)), getConfigSource(key)})
+	}
+	configTable.Render()
+}
+
+// This function will be called when the config validate command is called.
+func initializeConfigValidateCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc config validate --help' to view help."))
+	}
+	setLogLevel()
+	logger.Debug("[config validate] command called")
+
+	if fileConfig == nil {
+		util.HandleErrorAndExit(errors.New("Config file not found. Use the '--config' flag to set the location of the config file."))
+	}
+	problems, warnings := validateConfig(getTopLevelSettings(fileConfig), "", true)
+	problems = append(problems, validateEnvironmentVariables()...)
+	for _, warning := range warnings {
+		util.PrintWarning(warning)
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			util.PrintWarning(problem)
+		}
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Config file '%s' is invalid. %d problem(s) found.",
+			fileConfig.ConfigFileUsed(), len(problems))))
+	}
+	util.PrintInfo(fmt.Sprintf("Config file '%s' is valid.", fileConfig.ConfigFileUsed()))
+}
+
+// This function will return the top level keys and values in the given config. Values of the keys are not flattened
+// because keys such as product versions contain dots.
+func getTopLevelSettings(config *viper.Viper) map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, key := range config.AllKeys() {
+		topLevelKey := strings.Split(key, ".")[0]
+		settings[topLevelKey] = config.Get(topLevelKey)
+	}
+	return settings
+}
+
+// This function will check the types and the values of the given configs. The prefix is added to the keys in the
+// returned problems. Profiles are validated only at the top level of the config file.
+func validateConfig(settings map[string]interface{}, prefix string, isTopLevel bool) (problems, warnings []string) {
+	for _, key := range getSortedKeys(settings) {
+		value := settings[key]
+		fullKey := prefix + key
+		switch strings.ToUpper(key) {
+		case constant.CHECK_MD5_DISABLED:
+			if _, err := cast.ToBoolE(value); err != nil {
+				problems = append(problems, fmt.Sprintf("'%s' should be true or false.", fullKey))
+			}
+		case constant.KNOWN_UPDATES_DIRECTORY, constant.UPDATE_REGISTRY, constant.OUTPUT_LOCATION, constant.SIGNING_KEY,
+			constant.VERIFICATION_KEY:
+			if _, err := cast.ToStringE(value); err != nil {
+				problems = append(problems, fmt.Sprintf("'%s' should be a string.", fullKey))
+			}
+		case constant.RESOURCE_FILES:
+			problems = append(problems, validateResourceFilesConfig(value, fullKey, true)...)
+		case constant.PLATFORM_VERSIONS:
+			platformVersions, err := cast.ToStringMapStringE(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("'%s' should be a map of platform versions to platform names.", fullKey))
+				continue
+			}
+			for _, platformVersion := range getSortedKeys(platformVersions) {
+				if err := util.ValidatePlatformVersion(platformVersion); err != nil {
+					problems = append(problems, fmt.Sprintf("'%s' contains an invalid platform version '%s'.", fullKey, platformVersion))
+				}
+			}
+		case constant.PRODUCT_CATALOG:
+			products, err := cast.ToStringMapE(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("'%s' should be a map of product names to product versions.", fullKey))
+				continue
+			}
+			for _, productName := range getSortedKeys(products) {
+				versions, err := cast.ToStringMapStringE(products[productName])
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("'%s.%s' should be a map of product versions to platform versions.",
+						fullKey, productName))
+					continue
+				}
+				for _, productVersion := range getSortedKeys(versions) {
+					if err := util.ValidatePlatformVersion(versions[productVersion]); err != nil {
+						problems = append(problems, fmt.Sprintf("'%s.%s.%s' has an invalid platform version '%s'.",
+							fullKey, productName, productVersion, versions[productVersion]))
+					}
+				}
+			}
+		case constant.PROFILE:
+			if !isTopLevel {
+				problems = append(problems, fmt.Sprintf("'%s' cannot be set in a profile.", fullKey))
+			}
+		case constant.PROFILES:
+			if !isTopLevel {
+				problems = append(problems, fmt.Sprintf("'%s' cannot be set in a profile.", fullKey))
+				continue
+			}
+			profiles, err := cast.ToStringMapE(value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("'%s' should be a map of profile names to configs.", fullKey))
+				continue
+			}
+			for _, profileName := range getSortedKeys(profiles) {
+				profileSettings, err := cast.ToStringMapE(profiles[profileName])
+				if err != nil {
+					problems = append(problems, fmt.Sprintf("'%s.%s' should be a map of configs.", fullKey, profileName))
+					continue
+				}
+				profileProblems, profileWarnings := validateConfig(profileSettings, fullKey + "." + profileName + ".", false)
+				problems = append(problems, profileProblems...)
+				warnings = append(warnings, profileWarnings...)
+			}
+		default:
+			warnings = append(warnings, fmt.Sprintf("Unknown config '%s' is ignored.", fullKey))
+		}
+	}
+	if isTopLevel {
+		if profileName, found := settings[strings.ToLower(constant.PROFILE)]; found {
+			profiles := cast.ToStringMap(settings[strings.ToLower(constant.PROFILES)])
+			if _, found := profiles[strings.ToLower(cast.ToString(profileName))]; !found {
+				problems = append(problems, fmt.Sprintf("Profile '%s' set in '%s' not found in '%s'.", cast.ToString(profileName),
+					strings.ToLower(constant.PROFILE), strings.ToLower(constant.PROFILES)))
+			}
+		}
+	}
+	return problems, warnings
+}
+
+// This function will check the configs given using environment variables in the same way as the configs in the config
+// file. Ex: WUMUC_PLATFORM_VERSIONS should be a JSON object. Lists of resource files are separated by commas, so any
+// value is valid for them.
+func validateEnvironmentVariables() []string {
+	problems := make([]string, 0)
+	for _, key := range configKeys {
+		environmentVariableName := getEnvironmentVariableName(key)
+		value := os.Getenv(environmentVariableName)
+		if len(value) == 0 || key == constant.RESOURCE_FILES_MANDATORY || key == constant.RESOURCE_FILES_OPTIONAL ||
+			key == constant.RESOURCE_FILES_SKIP {
+			continue
+		}
+		settings := map[string]interface{}{strings.ToLower(key): value}
+		if key == constant.RESOURCE_FILES_PRODUCTS {
+			settings = map[string]interface{}{
+				strings.ToLower(constant.RESOURCE_FILES): map[string]interface{}{strings.ToLower(constant.PRODUCTS): value},
+			}
+		}
+		keyProblems, _ := validateConfig(settings, "", false)
+		for _, problem := range keyProblems {
+			problems = append(problems, fmt.Sprintf("%s Value is set using the environment variable %s.", problem,
+				environmentVariableName))
+		}
+	}
+	return problems
+}
+
+// This function will check the given resource_files config. Product specific resource files are checked if
+// isProductsAllowed is true.
+func validateResourceFilesConfig(value interface{}, key string, isProductsAllowed bool) []string {
+	problems := make([]string, 0)
+	resourceFiles, err := cast.ToStringMapE(value)
+	if err != nil {
+		return append(problems, fmt.Sprintf("'%s' should be a map.", key))
+	}
+	for _, name := range getSortedKeys(resourceFiles) {
+		switch strings.ToUpper(name) {
+		case constant.MANDATORY, constant.OPTIONAL, constant.SKIP:
+			if _, isList := resourceFiles[name].([]interface{}); !isList && resourceFiles[name] != nil {
+				problems = append(problems, fmt.Sprintf("'%s.%s' should be a list of file patterns.", key, name))
+			}
+		case constant.PRODUCTS:
+			products, err := cast.ToStringMapE(resourceFiles[name])
+			if !isProductsAllowed || err != nil {
+				problems = append(problems, fmt.Sprintf("'%s.%s' should be a map of products to resource files.", key, name))
+				continue
+			}
+			for _, product := range getSortedKeys(products) {
+				problems = append(problems, validateResourceFilesConfig(products[product], key + "." + name + "." + product,
+					false)...)
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("Unknown key '%s.%s'. It should be one of mandatory, optional, skip "+
+				"or products.", key, name))
+		}
+	}
+	return problems
+}
+
+// This function will return the value of the given config in the same way as it is used by the other commands. Values
+// given using environment variables are parsed. This function will exit if a map is invalid.
+func getEffectiveConfigValue(key string) interface{} {
+	switch key {
+	case constant.RESOURCE_FILES_MANDATORY, constant.RESOURCE_FILES_OPTIONAL, constant.RESOURCE_FILES_SKIP:
+		return getStringSliceConfig(key)
+	case constant.RESOURCE_FILES_PRODUCTS:
+		// getProductResourceFiles exits if the value is invalid
+		if len(getProductResourceFiles()) == 0 {
+			return nil
+		}
+		return viper.GetStringMap(key)
+	case constant.PLATFORM_VERSIONS:
+		return getPlatformVersions()
+	case constant.PRODUCT_CATALOG:
+		return getProductCatalog()
+	}
+	return viper.Get(key)
+}
+
+// This function will return the value of a config as a string which can be printed in a table. Lists and maps are
+// printed in YAML format.
+func formatConfigValue(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return ""
+	case []interface{}, []string, map[string]interface{}, map[interface{}]interface{}, map[string]string,
+		map[string]map[string]string:
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return strings.TrimSpace(string(data))
+	}
+	return cast.ToString(value)
+}
+
+// This function will return the keys of the given map in sorted order.
+func getSortedKeys(values interface{}) []string {
+	keys := make([]string, 0)
+	for _, key := range reflect.ValueOf(values).MapKeys() {
+		keys = append(keys, fmt.Sprintf("%v", key.Interface()))
+	}
+	sort.Strings(keys)
+	return keys
+}
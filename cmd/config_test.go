@@ -0,0 +1,135 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"github.com/wso2/wum-uc/constant"
+	"gopkg.in/yaml.v2"
+)
+
+func TestValidateConfig(t *testing.T) {
+	data := []byte(`check_md5_disabled: true
+resource_files:
+  mandatory:
+  - update-descriptor.yaml
+  products:
+    wso2am:
+      optional:
+      - docs/**
+product_catalog:
+  wso2am:
+    2.0.0: 4.4.0
+profile: apim
+profiles:
+  apim:
+    resource_files:
+      skip:
+      - "*.md"
+  broken:
+    check_md5_disabled: maybe
+    resource_files:
+      mandatory: LICENSE.txt
+    platform_versions:
+      "4.4": wilkes
+    product_catalog:
+      wso2esb:
+        4.9.0: 4.4
+    profile: apim
+    lint: {}
+`)
+	settings := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		t.Fatal(err)
+	}
+	problems, warnings := validateConfig(cast.ToStringMap(settings), "", true)
+
+	expectedProblems := []string{
+		"'profiles.broken.check_md5_disabled' should be true or false.",
+		"'profiles.broken.platform_versions' contains an invalid platform version '4.4'.",
+		"'profiles.broken.product_catalog.wso2esb.4.9.0' has an invalid platform version '4.4'.",
+		"'profiles.broken.profile' cannot be set in a profile.",
+		"'profiles.broken.resource_files.mandatory' should be a list of file patterns.",
+	}
+	if strings.Join(problems, "\n") != strings.Join(expectedProblems, "\n") {
+		t.Errorf("Test failed, expected: %s, actual: %s", expectedProblems, problems)
+	}
+	expectedWarnings := []string{"Unknown config 'profiles.broken.lint' is ignored."}
+	if strings.Join(warnings, "\n") != strings.Join(expectedWarnings, "\n") {
+		t.Errorf("Test failed, expected: %s, actual: %s", expectedWarnings, warnings)
+	}
+
+	problems, _ = validateConfig(map[string]interface{}{"profile": "other"}, "", true)
+	if len(problems) != 1 || !strings.Contains(problems[0], "Profile 'other'") {
+		t.Errorf("Test failed, expected: %s, actual: %s", "Profile 'other' not found", problems)
+	}
+}
+
+func TestEnvironmentVariableOverrides(t *testing.T) {
+	defer viper.Reset()
+	setDefaultValues()
+	setEnvironmentVariableOverrides()
+
+	os.Setenv("WUMUC_RESOURCE_FILES_SKIP", "README.txt, *.md")
+	defer os.Unsetenv("WUMUC_RESOURCE_FILES_SKIP")
+	os.Setenv("WUMUC_CHECK_MD5_DISABLED", "true")
+	defer os.Unsetenv("WUMUC_CHECK_MD5_DISABLED")
+
+	resourceFiles := getResourceFiles()
+	if strings.Join(resourceFiles.Skip, ",") != "README.txt,*.md" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "README.txt,*.md", resourceFiles.Skip)
+	}
+	if strings.Join(resourceFiles.Mandatory, ",") != "update-descriptor.yaml,LICENSE.txt" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "update-descriptor.yaml,LICENSE.txt", resourceFiles.Mandatory)
+	}
+	if !viper.GetBool(constant.CHECK_MD5_DISABLED) {
+		t.Errorf("Test failed, expected: %v, actual: %v", true, viper.GetBool(constant.CHECK_MD5_DISABLED))
+	}
+
+	expectedSources := map[string]string{
+		constant.RESOURCE_FILES_SKIP: "environment variable WUMUC_RESOURCE_FILES_SKIP",
+		constant.RESOURCE_FILES_MANDATORY: "default",
+		constant.VERIFICATION_KEY: "not set",
+	}
+	for key, expected := range expectedSources {
+		if actual := getConfigSource(key); actual != expected {
+			t.Errorf("Test failed, expected: %s, actual: %s", expected, actual)
+		}
+	}
+}
+
+func TestValidateEnvironmentVariables(t *testing.T) {
+	os.Setenv("WUMUC_PLATFORM_VERSIONS", `{"4.4.0": "wilkes"`)
+	defer os.Unsetenv("WUMUC_PLATFORM_VERSIONS")
+	os.Setenv("WUMUC_PRODUCT_CATALOG", `{"wso2esb": {"4.9.0": "4.4"}}`)
+	defer os.Unsetenv("WUMUC_PRODUCT_CATALOG")
+	os.Setenv("WUMUC_RESOURCE_FILES_SKIP", "README.txt, *.md")
+	defer os.Unsetenv("WUMUC_RESOURCE_FILES_SKIP")
+
+	expectedProblems := []string{
+		"'platform_versions' should be a map of platform versions to platform names. Value is set using the " +
+			"environment variable WUMUC_PLATFORM_VERSIONS.",
+		"'product_catalog.wso2esb.4.9.0' has an invalid platform version '4.4'. Value is set using the environment " +
+			"variable WUMUC_PRODUCT_CATALOG.",
+	}
+	problems := validateEnvironmentVariables()
+	if strings.Join(problems, "\n") != strings.Join(expectedProblems, "\n") {
+		t.Errorf("Test failed, expected: %s, actual: %s", expectedProblems, problems)
+	}
+
+	if _, err := parsePlatformVersions(os.Getenv("WUMUC_PLATFORM_VERSIONS")); err == nil {
+		t.Error("Test failed. Expected an error for invalid JSON")
+	}
+	productCatalog, err := parseProductCatalog(os.Getenv("WUMUC_PRODUCT_CATALOG"))
+	if err != nil || productCatalog["wso2esb"]["4.9.0"] != "4.4" {
+		t.Errorf("Test failed, expected: %s, actual: %v (%v)", "4.4", productCatalog, err)
+	}
+	if _, err := parseProductCatalog(`{"wso2esb": "4.9.0"}`); err == nil {
+		t.Error("Test failed. Expected an error for invalid product versions")
+	}
+}
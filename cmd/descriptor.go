@@ -0,0 +1,331 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/pkg/update"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	descriptorCmdUse = "descriptor"
+	descriptorCmdShortDesc = "Manage '" + constant.UPDATE_DESCRIPTOR_FILE + "' files"
+	descriptorCmdLongDesc = dedent.Dedent(`
+		This command contains sub commands which can be used to manage the
+		'update-descriptor.yaml' file of an update.`)
+
+	migrateCmdUse = "migrate [<update_dir>]"
+	migrateCmdShortDesc = "Migrate '" + constant.UPDATE_DESCRIPTOR_FILE + "' to the latest version"
+	migrateCmdLongDesc = dedent.Dedent(`
+		This command will migrate a version 1 'update-descriptor.yaml' file
+		to a version 2 'update-descriptor.yaml' file. If the user does not
+		specify a directory, it will use the current working directory.
+		Comma separated products in the 'applies_to' field will be
+		converted to a list.`)
+
+	checkCmdUse = "check [<update_dir>]"
+	checkCmdShortDesc = "Check '" + constant.UPDATE_DESCRIPTOR_FILE + "' for placeholders"
+	checkCmdLongDesc = dedent.Dedent(`
+		This command will list the placeholders added by the 'init' command
+		which are not yet replaced in the 'update-descriptor.yaml' file with
+		the field and the line of each placeholder. If the user does not
+		specify a directory, it will use the current working directory.
+		The command fails if placeholders are found unless the
+		--allow-placeholders flag is used.`)
+
+	setCmdUse = "set <field> <value> [<update_dir>]"
+	setCmdShortDesc = "Set a field of '" + constant.UPDATE_DESCRIPTOR_FILE + "'"
+	setCmdLongDesc = dedent.Dedent(`
+		This command will set the value of the given field in the
+		'update-descriptor.yaml' file. The value is validated before saving
+		the file. Supported fields are update_number, platform_version,
+		platform_name, applies_to, description, security_severity,
+		requires and supersedes. Lists are given as comma separated values.
+		If the user does not specify a directory, it will use the current
+		working directory.`)
+
+	addBugFixCmdUse = "add-bugfix <KEY> [<summary>]"
+	addBugFixCmdShortDesc = "Add a bug fix to '" + constant.UPDATE_DESCRIPTOR_FILE + "'"
+	addBugFixCmdLongDesc = dedent.Dedent(`
+		This command will add the given JIRA key to the 'bug_fixes' field of
+		the 'update-descriptor.yaml' file in the current working directory.
+		If the summary is not given, it will be fetched from the JIRA.
+		Placeholder and 'N/A' entries will be removed.`)
+
+	removeBugFixCmdUse = "remove-bugfix <KEY>"
+	removeBugFixCmdShortDesc = "Remove a bug fix from '" + constant.UPDATE_DESCRIPTOR_FILE + "'"
+	removeBugFixCmdLongDesc = dedent.Dedent(`
+		This command will remove the given JIRA key from the 'bug_fixes'
+		field of the 'update-descriptor.yaml' file in the current working
+		directory.`)
+
+	showCmdUse = "show [<update_dir>]"
+	showCmdShortDesc = "Show the fields of '" + constant.UPDATE_DESCRIPTOR_FILE + "'"
+	showCmdLongDesc = dedent.Dedent(`
+		This command will print the fields of the 'update-descriptor.yaml'
+		file. If the user does not specify a directory, it will use the
+		current working directory.`)
+)
+
+// Directory which contains the update-descriptor.yaml to edit when using the add-bugfix and remove-bugfix commands.
+var descriptorDirectory string
+
+// descriptorCmd represents the descriptor command.
+var descriptorCmd = &cobra.Command{
+	Use: descriptorCmdUse,
+	Short: descriptorCmdShortDesc,
+	Long: descriptorCmdLongDesc,
+}
+
+// migrateCmd represents the descriptor migrate command.
+var migrateCmd = &cobra.Command{
+	Use: migrateCmdUse,
+	Short: migrateCmdShortDesc,
+	Long: migrateCmdLongDesc,
+	Run: initializeMigrateCommand,
+}
+
+// checkCmd represents the descriptor check command.
+var checkCmd = &cobra.Command{
+	Use: checkCmdUse,
+	Short: checkCmdShortDesc,
+	Long: checkCmdLongDesc,
+	Run: initializeCheckCommand,
+}
+
+// setCmd represents the descriptor set command.
+var setCmd = &cobra.Command{
+	Use: setCmdUse,
+	Short: setCmdShortDesc,
+	Long: setCmdLongDesc,
+	Run: initializeSetCommand,
+}
+
+// addBugFixCmd represents the descriptor add-bugfix command.
+var addBugFixCmd = &cobra.Command{
+	Use: addBugFixCmdUse,
+	Short: addBugFixCmdShortDesc,
+	Long: addBugFixCmdLongDesc,
+	Run: initializeAddBugFixCommand,
+}
+
+// removeBugFixCmd represents the descriptor remove-bugfix command.
+var removeBugFixCmd = &cobra.Command{
+	Use: removeBugFixCmdUse,
+	Short: removeBugFixCmdShortDesc,
+	Long: removeBugFixCmdLongDesc,
+	Run: initializeRemoveBugFixCommand,
+}
+
+// showCmd represents the descriptor show command.
+var showCmd = &cobra.Command{
+	Use: showCmdUse,
+	Short: showCmdShortDesc,
+	Long: showCmdLongDesc,
+	Run: initializeShowCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(descriptorCmd)
+	descriptorCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+	migrateCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+
+	descriptorCmd.AddCommand(checkCmd)
+	checkCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+	checkCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+	checkCmd.Flags().BoolVar(&isPlaceholdersAllowed, "allow-placeholders", false, "Report placeholders as warnings instead of errors")
+
+	for _, command := range []*cobra.Command{setCmd, addBugFixCmd, removeBugFixCmd, showCmd} {
+		descriptorCmd.AddCommand(command)
+		command.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+		command.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+	}
+	for _, command := range []*cobra.Command{addBugFixCmd, removeBugFixCmd} {
+		command.Flags().StringVar(&descriptorDirectory, "dir", "./", "Directory which contains the '" + constant.UPDATE_DESCRIPTOR_FILE + "'")
+	}
+}
+
+// This function will be called when the descriptor migrate command is called.
+func initializeMigrateCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 0:
+		migrateUpdateDescriptor("./")
+	case 1:
+		migrateUpdateDescriptor(args[0])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc descriptor migrate --help' to view help."))
+	}
+}
+
+// This function will migrate the update-descriptor.yaml in the given directory to the latest descriptor version.
+func migrateUpdateDescriptor(updateDirectoryPath string) {
+	setLogLevel()
+	logger.Debug("[descriptor migrate] command called")
+
+	updateDescriptorPath := filepath.Join(updateDirectoryPath, constant.UPDATE_DESCRIPTOR_FILE)
+	exists, err := util.IsFileExists(updateDescriptorPath)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while reading the '%v'", constant.UPDATE_DESCRIPTOR_FILE))
+	if !exists {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("'%s' not found at '%s' directory.", constant.UPDATE_DESCRIPTOR_FILE, updateDirectoryPath)))
+	}
+
+	descriptorVersion, err := util.GetDescriptorVersionOfFile(constant.UPDATE_DESCRIPTOR_FILE, updateDirectoryPath)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+	if descriptorVersion == constant.DESCRIPTOR_VERSION_2 {
+		util.PrintInfo(fmt.Sprintf("'%s' is already a version %s descriptor.", updateDescriptorPath, descriptorVersion))
+		return
+	}
+
+	updateDescriptor, err := util.LoadUpdateDescriptor(constant.UPDATE_DESCRIPTOR_FILE, updateDirectoryPath)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+
+	updateDescriptorV2 := util.MigrateUpdateDescriptor(updateDescriptor)
+	data, err := util.MarshalUpdateDescriptorV2(updateDescriptorV2)
+	util.HandleErrorAndExit(err, "Error occurred while marshalling the update-descriptor.")
+
+	err = ioutil.WriteFile(updateDescriptorPath, data, 0600)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while saving the '%v'.", constant.UPDATE_DESCRIPTOR_FILE))
+	util.PrintInfo(fmt.Sprintf("'%s' successfully migrated to version %s.", updateDescriptorPath, constant.DESCRIPTOR_VERSION_2))
+
+	// Migration does not fix invalid values. So warn the user if the migrated descriptor is not valid.
+	err = util.ValidateUpdateDescriptorV2(updateDescriptorV2)
+	if err != nil {
+		util.PrintWarning(fmt.Sprintf("Migrated '%s' is not valid. %s", constant.UPDATE_DESCRIPTOR_FILE, err.Error()))
+	}
+}
+
+// This function will be called when the descriptor check command is called.
+func initializeCheckCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 0:
+		checkUpdateDescriptor("./")
+	case 1:
+		checkUpdateDescriptor(args[0])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc descriptor check --help' to view help."))
+	}
+}
+
+// This function will print the placeholders in the update-descriptor.yaml in the given directory.
+func checkUpdateDescriptor(updateDirectoryPath string) {
+	setLogLevel()
+	logger.Debug("[descriptor check] command called")
+
+	placeholders, err := update.CheckDescriptor(updateDirectoryPath, isPlaceholdersAllowed)
+	if len(placeholders) == 0 {
+		handleUpdateErrorAndExit(err)
+		util.PrintInfo(fmt.Sprintf("No placeholders found in '%s'.", constant.UPDATE_DESCRIPTOR_FILE))
+		return
+	}
+	placeholdersTable := tablewriter.NewWriter(os.Stdout)
+	placeholdersTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	placeholdersTable.SetHeader([]string{"Line", "Field", "Placeholder"})
+	for _, placeholder := range placeholders {
+		placeholdersTable.Append([]string{strconv.Itoa(placeholder.Line), placeholder.Field, placeholder.Placeholder})
+	}
+	placeholdersTable.Render()
+	handleUpdateErrorAndExit(err)
+	util.PrintWarning(fmt.Sprintf("%d placeholder(s) found in '%s'.", len(placeholders), constant.UPDATE_DESCRIPTOR_FILE))
+}
+
+// This function will be called when the descriptor set command is called.
+func initializeSetCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 2:
+		setDescriptorField(args[0], args[1], "./")
+	case 3:
+		setDescriptorField(args[0], args[1], args[2])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc descriptor set --help' to view help."))
+	}
+}
+
+// This function will set the given field of the update-descriptor.yaml in the given directory.
+func setDescriptorField(field, value, updateDirectoryPath string) {
+	setLogLevel()
+	logger.Debug("[descriptor set] command called")
+
+	result, err := update.SetDescriptorField(updateDirectoryPath, field, value)
+	handleUpdateErrorAndExit(err)
+	printResult(result)
+}
+
+// This function will be called when the descriptor add-bugfix command is called.
+func initializeAddBugFixCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 1:
+		addBugFix(args[0], "")
+	case 2:
+		addBugFix(args[0], args[1])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc descriptor add-bugfix --help' to view help."))
+	}
+}
+
+// This function will add the given bug fix to the update-descriptor.yaml.
+func addBugFix(key, summary string) {
+	setLogLevel()
+	logger.Debug("[descriptor add-bugfix] command called")
+
+	result, err := update.AddBugFix(descriptorDirectory, key, summary)
+	handleUpdateErrorAndExit(err)
+	printResult(result)
+}
+
+// This function will be called when the descriptor remove-bugfix command is called.
+func initializeRemoveBugFixCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc descriptor remove-bugfix --help' to view help."))
+	}
+	setLogLevel()
+	logger.Debug("[descriptor remove-bugfix] command called")
+
+	result, err := update.RemoveBugFix(descriptorDirectory, args[0])
+	handleUpdateErrorAndExit(err)
+	printResult(result)
+}
+
+// This function will be called when the descriptor show command is called.
+func initializeShowCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 0:
+		showUpdateDescriptor("./")
+	case 1:
+		showUpdateDescriptor(args[0])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc descriptor show --help' to view help."))
+	}
+}
+
+// This function will print the fields of the update-descriptor.yaml in the given directory.
+func showUpdateDescriptor(updateDirectoryPath string) {
+	setLogLevel()
+	logger.Debug("[descriptor show] command called")
+
+	fields, result, err := update.ShowDescriptor(updateDirectoryPath)
+	handleUpdateErrorAndExit(err)
+	fieldsTable := tablewriter.NewWriter(os.Stdout)
+	fieldsTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	fieldsTable.SetAutoWrapText(false)
+	fieldsTable.SetHeader([]string{"Field", "Value"})
+	for _, field := range fields {
+		fieldsTable.Append([]string{field.Name, field.Value})
+	}
+	fieldsTable.Render()
+	printResult(result)
+}
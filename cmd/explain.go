@@ -0,0 +1,77 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/wso2/wum-uc/pkg/update"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	explainCmdUse = "explain [<code>]"
+	explainCmdShortDesc = "Explain an error code"
+	explainCmdLongDesc = dedent.Dedent(`
+		This command will describe the cause and the fix of the given error
+		code. Error codes are printed by the create and validate commands
+		when they fail. If a code is not given, all error codes will be
+		listed.`)
+)
+
+// explainCmd represents the explain command.
+var explainCmd = &cobra.Command{
+	Use: explainCmdUse,
+	Short: explainCmdShortDesc,
+	Long: explainCmdLongDesc,
+	Run: initializeExplainCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(explainCmd)
+}
+
+// This function will be called when the explain command is called.
+func initializeExplainCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 0:
+		listErrorCodes()
+	case 1:
+		explainErrorCode(args[0])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc explain --help' to view help."))
+	}
+}
+
+// This function will print all error codes and their titles.
+func listErrorCodes() {
+	codesTable := tablewriter.NewWriter(os.Stdout)
+	codesTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	codesTable.SetHeader([]string{"Code", "Description"})
+	for _, code := range update.GetErrorCodes() {
+		explanation, _ := update.Explain(string(code))
+		codesTable.Append([]string{string(code), explanation.Title})
+	}
+	codesTable.Render()
+}
+
+// This function will print the cause and the fix of the given error code.
+func explainErrorCode(code string) {
+	explanation, found := update.Explain(code)
+	if !found {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Unknown error code '%s'. Run 'wum-uc explain' to view all error codes.", code)))
+	}
+	color.Set(color.Bold)
+	fmt.Println(fmt.Sprintf("%s - %s", explanation.Code, explanation.Title))
+	color.Unset()
+	fmt.Println(fmt.Sprintf("\nCause:\n\t%s", explanation.Cause))
+	fmt.Println(fmt.Sprintf("\nFix:\n\t%s", explanation.Fix))
+}
@@ -4,23 +4,26 @@ package cmd
 
 import (
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path"
 	"path/filepath"
-	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
 	"github.com/renstrom/dedent"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/pkg/update"
 	"github.com/wso2/wum-uc/util"
-	"gopkg.in/yaml.v2"
 )
 
+// Platform version given using the --platform-version flag.
+var initPlatformVersion string
+
 var (
 	initCmdUse = "init"
 	initCmdShortDesc = "Generate '" + constant.UPDATE_DESCRIPTOR_FILE + "' file template"
@@ -30,12 +33,14 @@ var (
 		working directory. It will fill the data using any available
 		README.txt file in the old patch format. If README.txt is not
 		found, it will fill values using default values which you need
-		to edit manually.`)
+		to edit manually. If the --interactive flag is given, it will
+		prompt for each field and validate the values as they are
+		entered.`)
 
 	initCmdExample = dedent.Dedent(`update_number: 0001
 platform_version: 4.4.0
 platform_name: wilkes
-applies_to: All the products based on carbon 4.4.1
+applies_to: wso2esb-4.9.0, wso2am-1.10.0
 bug_fixes:
   CARBON-15395: Upgrade Hazelcast version to 3.5.2
   <MORE_JIRAS_HERE>
@@ -67,6 +72,12 @@ func init() {
 
 	initCmd.Flags().BoolP("sample", "s", false, "Show sample file")
 	viper.BindPFlag(constant.SAMPLE, initCmd.Flags().Lookup("sample"))
+
+	initCmd.Flags().BoolP("interactive", "i", false, "Enter the values of the update descriptor interactively")
+	viper.BindPFlag(constant.INTERACTIVE, initCmd.Flags().Lookup("interactive"))
+
+	initCmd.Flags().BoolVar(&isNextNumberEnabled, "next-number", false, "Allocate the next free update number of the platform version")
+	initCmd.Flags().StringVar(&initPlatformVersion, "platform-version", "", "Platform version of the update")
 }
 
 //This function will be called when the create command is called.
@@ -101,10 +112,11 @@ func initDirectory(destination string) {
 	logger.Debug("Initializing started.")
 	// Check whether the provided directory exists
 	exists, err := util.IsDirectoryExists(destination)
+	util.HandleErrorAndExit(err)
 	logger.Debug(fmt.Sprintf("'%s' directory exists: %v", destination, exists))
 
 	// If the directory does not exists, prompt the user
-	skip := false
+	createDirectory := false
 	if !exists {
 		userInputLoop:
 		for {
@@ -119,57 +131,47 @@ func initDirectory(destination string) {
 			userPreference := util.ProcessUserPreference(preference)
 			switch(userPreference){
 			case constant.YES:
-				util.PrintInfo(fmt.Sprintf("'%s' directory does not exist. Creating '%s' directory.", destination, destination))
-				err := util.CreateDirectory(destination)
-				util.HandleErrorAndExit(err)
-				logger.Debug(fmt.Sprintf("'%s' directory created.", destination))
+				createDirectory = true
 				break userInputLoop
 			case constant.NO:
-				skip = true
 				break userInputLoop
 			default:
 				util.PrintError("Invalid preference. Enter Y for Yes or N for No.")
 			}
 		}
 	}
-	// If the skip is selected, exit
-	if skip {
-		util.HandleErrorAndExit(errors.New("Directory creation skipped. Please enter a valid directory."))
-	}
-
-	// Create a new update descriptor struct
-	updateDescriptor := util.UpdateDescriptor{}
 
-	// Process README.txt and parse values
-	processReadMe(destination, &updateDescriptor)
-
-	// Marshall the update descriptor struct
-	data, err := yaml.Marshal(&updateDescriptor)
-	util.HandleErrorAndExit(err)
-
-	dataString := string(data)
-	//remove " enclosing the update number
-	dataString = strings.Replace(dataString, "\"", "", -1)
-	logger.Debug(fmt.Sprintf("update-descriptor:\n%s", dataString))
-
-	// Construct the update descriptor file path
-	updateDescriptorFile := filepath.Join(destination, constant.UPDATE_DESCRIPTOR_FILE)
-	logger.Debug(fmt.Sprintf("updateDescriptorFile: %v", updateDescriptorFile))
-
-	// Save the update descriptor
-	file, err := os.OpenFile(
-		updateDescriptorFile,
-		os.O_WRONLY | os.O_TRUNC | os.O_CREATE,
-		0600,
-	)
-	util.HandleErrorAndExit(err)
-	defer file.Close()
-
-	// Write bytes to file
-	_, err = file.Write([]byte(dataString))
-	if err != nil {
-		util.HandleErrorAndExit(err)
-	}
+	result, err := update.Init(update.InitOptions{
+		Directory: destination,
+		CreateDirectory: createDirectory,
+		PlatformVersions: getPlatformVersions(),
+		Customize: func(updateDescriptor *util.UpdateDescriptor) error {
+			// Set the platform version given using the flag. Platform name is set using the platform version.
+			if len(initPlatformVersion) > 0 {
+				if err := util.ValidatePlatformVersion(initPlatformVersion); err != nil {
+					return err
+				}
+				updateDescriptor.Platform_version = initPlatformVersion
+				if platformName, found := getPlatformVersions()[initPlatformVersion]; found {
+					updateDescriptor.Platform_name = platformName
+				}
+			}
+			// If the interactive mode is enabled, prompt the user for each field. Values parsed from the
+			// README.txt will be used as the default values.
+			if viper.GetBool(constant.INTERACTIVE) {
+				logger.Debug("-i flag found. Reading values from the user.")
+				readUpdateDescriptorInteractively(updateDescriptor)
+			}
+			return nil
+		},
+		NextNumber: isNextNumberEnabled,
+		Allocation: update.AllocateOptions{
+			KnownUpdatesDirectory: viper.GetString(constant.KNOWN_UPDATES_DIRECTORY),
+			RegistryPath: viper.GetString(constant.UPDATE_REGISTRY),
+		},
+	})
+	printResult(result)
+	handleUpdateErrorAndExit(err)
 
 	// Get the absolute location
 	absDestination, err := filepath.Abs(destination)
@@ -185,152 +187,170 @@ func initDirectory(destination string) {
 	fmt.Println(fmt.Sprintf("\trun 'wum-uc init --sample' to view a sample '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
 }
 
-//This function will set default valued to the update-descriptor.yaml.
-func setUpdateDescriptorDefaultValues(updateDescriptor *util.UpdateDescriptor) {
-	logger.Debug("Setting default values:")
-	updateDescriptor.Update_number = constant.UPDATE_NO_DEFAULT
-	updateDescriptor.Platform_name = constant.PLATFORM_NAME_DEFAULT
-	updateDescriptor.Platform_version = constant.PLATFORM_VERSION_DEFAULT
-	updateDescriptor.Applies_to = constant.APPLIES_TO_DEFAULT
-	updateDescriptor.Description = constant.DESCRIPTION_DEFAULT
-	bugFixes := map[string]string{
-		constant.JIRA_KEY_DEFAULT: constant.JIRA_SUMMARY_DEFAULT,
-	}
-	updateDescriptor.Bug_fixes = bugFixes
-	logger.Debug(fmt.Sprintf("bug_fixes: %v", bugFixes))
-}
+//This function will prompt the user to enter each field of the update-descriptor.yaml. Each value is validated as it is
+// entered. Existing values in the given struct are used as the default values.
+func readUpdateDescriptorInteractively(updateDescriptor *util.UpdateDescriptor) {
+	updateDescriptor.Update_number = readFieldValue("Update number", updateDescriptor.Update_number,
+		constant.UPDATE_NO_DEFAULT, util.ValidateUpdateNumber)
 
-//This function will process the readme file and extract details to populate update-descriptor.yaml. If some data cannot
-// be extracted, it will add default value and continue.
-func processReadMe(directory string, updateDescriptor *util.UpdateDescriptor) {
-	logger.Debug("Processing README started")
-	// Construct the README.txt path
-	readMePath := path.Join(directory, constant.README_FILE)
-	logger.Debug(fmt.Sprintf("README Path: %v", readMePath))
-	// Check whether the README.txt file exists
-	_, err := os.Stat(readMePath)
-	if err != nil {
-		// If the file does not exist or any other error occur, return without printing warning messages
-		logger.Debug(fmt.Sprintf("%s not found", readMePath))
-		setUpdateDescriptorDefaultValues(updateDescriptor)
-		return
+	// Print the available platform versions so that the user can select one of them
+	platformsMap := getPlatformVersions()
+	platformVersions := make([]string, 0)
+	for platformVersion, platformName := range platformsMap {
+		platformVersions = append(platformVersions, fmt.Sprintf("%s(%s)", platformVersion, platformName))
 	}
-	// Read the README.txt file
-	data, err := ioutil.ReadFile(readMePath)
-	if err != nil {
-		// If any error occurs, return without printing warning messages
-		logger.Debug(fmt.Sprintf("Error occurred and processing README: %v", err))
-		setUpdateDescriptorDefaultValues(updateDescriptor)
-		return
+	sort.Strings(platformVersions)
+	if len(platformVersions) > 0 {
+		util.PrintInfo(fmt.Sprintf("Available platform versions: %s", strings.Join(platformVersions, ", ")))
 	}
+	updateDescriptor.Platform_version = readFieldValue("Platform version", updateDescriptor.Platform_version,
+		constant.PLATFORM_VERSION_DEFAULT, util.ValidatePlatformVersion)
 
-	logger.Debug("README.txt found")
-
-	// Convert the byte array to a string
-	stringData := string(data)
-	// Compile the regex
-	regex, err := regexp.Compile(constant.PATCH_ID_REGEX)
-	if err == nil {
-		result := regex.FindStringSubmatch(stringData)
-		logger.Trace(fmt.Sprintf("PATCH_ID_REGEX result: %v", result))
-		// Since the regex has 2 capturing groups, the result size will be 3 (because there is the full match)
-		// If not match found, the size will be 0. We check whether the result size is not 0 to make sure both
-		// capturing groups are identified.
-		if len(result) != 0 {
-			// Extract details
-			updateDescriptor.Update_number = result[2]
-			updateDescriptor.Platform_version = result[1]
-			platformsMap := viper.GetStringMapString(constant.PLATFORM_VERSIONS)
-			logger.Trace(fmt.Sprintf("Platform Map: %v", platformsMap))
-			// Get the platform details from the map
-			platformName, found := platformsMap[result[1]]
-			if found {
-				logger.Debug("PlatformName found in configs")
-				updateDescriptor.Platform_name = platformName
-			} else {
-				//If the platform name is not found, set default
-				logger.Debug("No matching platform name found for:", result[1])
-				updateDescriptor.Platform_name = constant.PLATFORM_NAME_DEFAULT
-			}
+	// Suggest the platform name using the platform version
+	platformName, found := platformsMap[updateDescriptor.Platform_version]
+	if !found {
+		logger.Debug("No matching platform name found for:", updateDescriptor.Platform_version)
+		platformName = updateDescriptor.Platform_name
+	}
+	updateDescriptor.Platform_name = readFieldValue("Platform name", platformName,
+		constant.PLATFORM_NAME_DEFAULT, util.ValidatePlatformName)
+
+	updateDescriptor.Applies_to = readAppliesTo(updateDescriptor)
+
+	updateDescriptor.Bug_fixes = readBugFixes(updateDescriptor.Bug_fixes)
+
+	updateDescriptor.Description = readDescription(updateDescriptor.Description)
+}
+
+//This function will read a single field value from the user. If the user does not enter a value, the default value will
+// be used unless it is a placeholder. The value will be read until it passes the given validation function.
+func readFieldValue(fieldName, defaultValue, placeholder string, validate func(string) error) string {
+	if defaultValue == placeholder {
+		defaultValue = ""
+	}
+	for {
+		if len(defaultValue) > 0 {
+			util.PrintInBold(fmt.Sprintf("%s [%s]: ", fieldName, defaultValue))
 		} else {
-			logger.Debug("PATCH_ID_REGEX results incorrect:", result)
+			util.PrintInBold(fmt.Sprintf("%s: ", fieldName))
+		}
+		value, err := util.GetUserInput()
+		util.HandleErrorAndExit(err, "Error occurred while getting input from the user.")
+		if len(value) == 0 {
+			value = defaultValue
+		}
+		err = validate(value)
+		if err != nil {
+			util.PrintError(err.Error())
+			continue
 		}
-	} else {
-		//If error occurred, set default values
-		logger.Debug(fmt.Sprintf("Error occurred while processing PATCH_ID_REGEX: %v", err))
-		updateDescriptor.Update_number = constant.UPDATE_NO_DEFAULT
-		updateDescriptor.Platform_name = constant.PLATFORM_NAME_DEFAULT
-		updateDescriptor.Platform_version = constant.PLATFORM_VERSION_DEFAULT
+		logger.Debug(fmt.Sprintf("%s: %s", fieldName, value))
+		return value
+	}
+}
+
+//This function will read the applies_to field. If the product catalog contains products which are based on the
+// selected platform version, they are shown to the user so that the user can select them using the indices.
+func readAppliesTo(updateDescriptor *util.UpdateDescriptor) string {
+	productCatalog := getProductCatalog()
+	products := util.GetProductsOfPlatformVersion(updateDescriptor.Platform_version, productCatalog)
+	logger.Debug(fmt.Sprintf("products: %v", products))
+	if len(products) == 0 {
+		return readFieldValue("Applies to", updateDescriptor.Applies_to, constant.APPLIES_TO_DEFAULT,
+			util.ValidateAppliesTo)
 	}
 
-	// Compile the regex
-	regex, err = regexp.Compile(constant.APPLIES_TO_REGEX)
-	if err == nil {
-		result := regex.FindStringSubmatch(stringData)
-		logger.Trace(fmt.Sprintf("APPLIES_TO_REGEX result: %v", result))
-		// In the README, Associated Jiras section might not appear. If it does appear, result size will be 2.
-		// If it does not appear, result size will be 3.
-		if len(result) == 2 {
-			// If the result size is 2, we know that 1st index contains the 1st capturing group.
-			updateDescriptor.Applies_to = util.ProcessString(result[1], ", ", true)
-		} else if len(result) == 3 {
-			// If the result size is 3, 1st or 2nd string might contain the match. So we concat them
-			// together and trim the spaces. If one field has an empty string, it will be trimmed.
-			updateDescriptor.Applies_to = util.ProcessString(strings.TrimSpace(result[1] + result[2]), ", ", true)
+	productsTable := tablewriter.NewWriter(os.Stdout)
+	productsTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	productsTable.SetHeader([]string{"Index", "Product"})
+	for i, product := range products {
+		productsTable.Append([]string{strconv.Itoa(i + 1), product})
+	}
+	productsTable.Render()
+
+	appliesTo := readFieldValue("Applies to [Indices or products separated by commas]", updateDescriptor.Applies_to,
+		constant.APPLIES_TO_DEFAULT, func(value string) error {
+			selectedProducts := getSelectedProducts(value, products)
+			if err := util.ValidateAppliesTo(selectedProducts); err != nil {
+				return err
+			}
+			return util.ValidateProducts(selectedProducts, updateDescriptor.Platform_version, productCatalog)
+		})
+	return getSelectedProducts(appliesTo, products)
+}
+
+//This function will convert the given comma separated indices or products to a comma separated list of products.
+func getSelectedProducts(value string, products []string) string {
+	selectedProducts := make([]string, 0)
+	for _, selection := range util.GetProductsInAppliesTo(value) {
+		index, err := strconv.Atoi(selection)
+		if err == nil && index >= 1 && index <= len(products) {
+			selectedProducts = append(selectedProducts, products[index - 1])
 		} else {
-			logger.Debug("No matching results found for APPLIES_TO_REGEX:", result)
+			selectedProducts = append(selectedProducts, selection)
 		}
-	} else {
-		//If error occurred, set default value
-		logger.Debug(fmt.Sprintf("Error occurred while processing APPLIES_TO_REGEX: %v", err))
-		updateDescriptor.Applies_to = constant.APPLIES_TO_DEFAULT
 	}
+	return strings.Join(selectedProducts, ", ")
+}
 
-	// Compile the regex
-	regex, err = regexp.Compile(constant.ASSOCIATED_JIRAS_REGEX)
-	if err == nil {
-		// Get all matches because there might be multiple Jiras.
-		allResult := regex.FindAllStringSubmatch(stringData, -1)
-		logger.Trace(fmt.Sprintf("APPLIES_TO_REGEX result: %v", allResult))
-		updateDescriptor.Bug_fixes = make(map[string]string)
-		// If no Jiras found, set 'N/A: N/A' as the value
-		if len(allResult) == 0 {
-			logger.Debug("No matching results found for ASSOCIATED_JIRAS_REGEX. Setting default values.")
-			updateDescriptor.Bug_fixes[constant.JIRA_NA] = constant.JIRA_NA
-		} else {
-			// If Jiras found, get summary for all Jiras
-			logger.Debug("Matching results found for ASSOCIATED_JIRAS_REGEX")
-			for i, match := range allResult {
-				// Regex has a one capturing group. So the jira ID will be in the 1st index.
-				logger.Debug(fmt.Sprintf("%d: %s", i, match[1]))
-				logger.Debug(fmt.Sprintf("ASSOCIATED_JIRAS_REGEX results is correct: %v", match))
-				updateDescriptor.Bug_fixes[match[1]] = util.GetJiraSummary(match[1])
-			}
+//This function will read JIRA keys and their summaries from the user in a loop. Reading stops when the user enters an
+// empty JIRA key. If no JIRA keys are entered, existing bug fixes will be used.
+func readBugFixes(existingBugFixes map[string]string) map[string]string {
+	bugFixes := make(map[string]string)
+	util.PrintInfo("Enter the JIRA keys associated with this update. Enter an empty key to finish.")
+	for {
+		util.PrintInBold(fmt.Sprintf("JIRA key #%d: ", len(bugFixes) + 1))
+		jiraKey, err := util.GetUserInput()
+		util.HandleErrorAndExit(err, "Error occurred while getting input from the user.")
+		if len(jiraKey) == 0 {
+			break
+		}
+		if jiraKey == constant.JIRA_KEY_DEFAULT {
+			util.PrintError(fmt.Sprintf("'%s' is a placeholder. Please enter a valid JIRA key.", jiraKey))
+			continue
 		}
-	} else {
-		//If error occurred, set default values
-		logger.Debug(fmt.Sprintf("Error occurred while processing ASSOCIATED_JIRAS_REGEX: %v", err))
-		logger.Debug("Setting defailt values to bug_fixes")
-		updateDescriptor.Bug_fixes = make(map[string]string)
-		updateDescriptor.Bug_fixes[constant.JIRA_KEY_DEFAULT] = constant.JIRA_SUMMARY_DEFAULT
+		// Get the summary from the JIRA and use it as the default value
+		summary := readFieldValue("Summary", util.GetJiraSummary(jiraKey), constant.JIRA_SUMMARY_DEFAULT,
+			func(value string) error {
+				if len(value) == 0 {
+					return errors.New("Summary cannot be empty.")
+				}
+				return nil
+			})
+		bugFixes[jiraKey] = summary
 	}
+	if len(bugFixes) != 0 {
+		return bugFixes
+	}
+	// If the user did not enter any JIRA key, check whether the existing bug fixes are valid
+	_, containsPlaceholder := existingBugFixes[constant.JIRA_KEY_DEFAULT]
+	if util.ValidateBugFixes(existingBugFixes) == nil && !containsPlaceholder {
+		logger.Debug(fmt.Sprintf("Using existing bug fixes: %v", existingBugFixes))
+		return existingBugFixes
+	}
+	logger.Debug("No JIRA keys entered. Setting default values.")
+	bugFixes[constant.JIRA_NA] = constant.JIRA_NA
+	return bugFixes
+}
 
-	// Compile the regex
-	regex, err = regexp.Compile(constant.DESCRIPTION_REGEX)
-	if err == nil {
-		// Get the match
-		result := regex.FindStringSubmatch(stringData)
-		logger.Trace(fmt.Sprintf("DESCRIPTION_REGEX result: %v", result))
-		// If there is a match, process it and store it
-		if len(result) != 0 {
-			updateDescriptor.Description = util.ProcessString(result[1], "\n", false)
-		} else {
-			logger.Debug(fmt.Sprintf("No matching results found for DESCRIPTION_REGEX: %v", result))
+//This function will open the editor to read the description. The description will be read until it passes the
+// validation.
+func readDescription(defaultDescription string) string {
+	if defaultDescription == constant.DESCRIPTION_DEFAULT {
+		defaultDescription = ""
+	}
+	for {
+		util.PrintInBold("Press Enter to open the editor to enter the description: ")
+		_, err := util.GetUserInput()
+		util.HandleErrorAndExit(err, "Error occurred while getting input from the user.")
+		description, err := util.GetUserInputFromEditor(defaultDescription)
+		util.HandleErrorAndExit(err, "Error occurred while getting the description from the editor.")
+		err = util.ValidateDescription(description)
+		if err != nil {
+			util.PrintError(err.Error())
+			continue
 		}
-	} else {
-		//If error occurred, set default values
-		logger.Debug(fmt.Sprintf("Error occurred while processing DESCRIPTION_REGEX: %v", err))
-		updateDescriptor.Description = constant.DESCRIPTION_DEFAULT
+		logger.Debug(fmt.Sprintf("description: %s", description))
+		return description
 	}
-	logger.Debug("Processing README finished")
 }
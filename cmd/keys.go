@@ -0,0 +1,102 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	keysCmdUse = "keys"
+	keysCmdShortDesc = "Manage keys used to sign updates"
+	keysCmdLongDesc = dedent.Dedent(`
+		This command contains sub commands which are used to manage the
+		keys used to sign and verify updates.`)
+
+	keysGenerateCmdUse = "generate [<name>]"
+	keysGenerateCmdShortDesc = "Generate a new key pair"
+	keysGenerateCmdLongDesc = dedent.Dedent(`
+		This command will generate a new key pair which can be used to sign
+		updates with 'create --sign' and to verify them with '--verify'.
+		Private key will be saved to <name>.pem and public key will be saved
+		to <name>.pub.pem. If a name is not given, 'wum-uc' will be used.`)
+)
+
+// Type of the key which should be generated.
+var keyType string
+
+// keysCmd represents the keys command.
+var keysCmd = &cobra.Command{
+	Use: keysCmdUse,
+	Short: keysCmdShortDesc,
+	Long: keysCmdLongDesc,
+}
+
+// keysGenerateCmd represents the keys generate command.
+var keysGenerateCmd = &cobra.Command{
+	Use: keysGenerateCmdUse,
+	Short: keysGenerateCmdShortDesc,
+	Long: keysGenerateCmdLongDesc,
+	Run: initializeKeysGenerateCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(keysCmd)
+	keysCmd.AddCommand(keysGenerateCmd)
+
+	keysGenerateCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+	keysGenerateCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+
+	keysGenerateCmd.Flags().StringVar(&keyType, "type", constant.KEY_TYPE_ED25519,
+		fmt.Sprintf("Type of the key (%s or %s)", constant.KEY_TYPE_ED25519, constant.KEY_TYPE_RSA))
+}
+
+// This function will be called when the keys generate command is called.
+func initializeKeysGenerateCommand(cmd *cobra.Command, args []string) {
+	switch len(args) {
+	case 0:
+		generateKeys("wum-uc")
+	case 1:
+		generateKeys(args[0])
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc keys generate --help' to view help."))
+	}
+}
+
+// This function will generate a new key pair and save the keys using the given name.
+func generateKeys(name string) {
+	setLogLevel()
+	logger.Debug("[keys generate] command called")
+
+	privateKeyPath := name + constant.PRIVATE_KEY_EXTENSION
+	publicKeyPath := name + constant.PUBLIC_KEY_EXTENSION
+
+	// Do not overwrite existing keys
+	for _, keyPath := range []string{privateKeyPath, publicKeyPath} {
+		exists, err := util.IsFileExists(keyPath)
+		util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while checking '%s'", keyPath))
+		if exists {
+			util.HandleErrorAndExit(errors.New(fmt.Sprintf("'%s' already exists.", keyPath)))
+		}
+	}
+
+	privateKey, publicKey, err := util.GenerateKeys(keyType)
+	util.HandleErrorAndExit(err, "Error occurred while generating the keys.")
+
+	err = ioutil.WriteFile(privateKeyPath, privateKey, 0600)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while saving '%s'.", privateKeyPath))
+	err = ioutil.WriteFile(publicKeyPath, publicKey, 0644)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while saving '%s'.", publicKeyPath))
+
+	util.PrintInfo(fmt.Sprintf("Private key saved to '%s'. Keep this file secure.", privateKeyPath))
+	util.PrintInfo(fmt.Sprintf("Public key saved to '%s'.", publicKeyPath))
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/wso2/wum-uc/pkg/update"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	notesCmdUse = "notes <update_loc>..."
+	notesCmdShortDesc = "Generate release notes from updates"
+	notesCmdLongDesc = dedent.Dedent(`
+		This command will generate release notes from the bug fixes,
+		description, applies_to, instructions and file changes of the given
+		update zips. Updates are grouped by the platform version. Notes can
+		be generated in Markdown, HTML or plain text. The layout can be
+		changed using a Go text/template file.`)
+)
+
+// Values used to generate release notes.
+var (
+	notesFormat string
+	notesTemplatePath string
+	notesOutputPath string
+)
+
+// notesCmd represents the notes command.
+var notesCmd = &cobra.Command{
+	Use: notesCmdUse,
+	Short: notesCmdShortDesc,
+	Long: notesCmdLongDesc,
+	Run: initializeNotesCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(notesCmd)
+
+	notesCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+	notesCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+
+	notesCmd.Flags().StringVarP(&notesFormat, "format", "f", update.NotesFormatMarkdown,
+		fmt.Sprintf("Format of the release notes (%s)", strings.Join(update.GetNotesFormats(), ", ")))
+	notesCmd.Flags().StringVar(&notesTemplatePath, "template", "", "Go template file used instead of the built-in template")
+	notesCmd.Flags().StringVarP(&notesOutputPath, "output", "o", "", "File where the release notes should be saved")
+}
+
+// This function will be called when the notes command is called.
+func initializeNotesCommand(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc notes --help' to view help."))
+	}
+	setLogLevel()
+	logger.Debug("[notes] command called")
+
+	// Notes are rendered into a buffer first so that the output file is not created or changed if the generation fails
+	buffer := bytes.Buffer{}
+	err := update.GenerateNotes(update.NotesOptions{
+		UpdateFilePaths: args,
+		Format: notesFormat,
+		TemplatePath: notesTemplatePath,
+	}, &buffer)
+	handleUpdateErrorAndExit(err)
+	if len(notesOutputPath) == 0 {
+		_, err = buffer.WriteTo(os.Stdout)
+		util.HandleErrorAndExit(err, "Error occurred while printing the release notes.")
+		return
+	}
+	err = ioutil.WriteFile(notesOutputPath, buffer.Bytes(), 0644)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while writing '%s'.", notesOutputPath))
+	util.PrintInfo(fmt.Sprintf("Release notes saved to '%s'.", notesOutputPath))
+}
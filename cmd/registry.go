@@ -0,0 +1,176 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/pkg/update"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	registryCmdUse = "registry"
+	registryCmdShortDesc = "Manage the registry of updates"
+	registryCmdLongDesc = dedent.Dedent(`
+		This command contains sub commands which are used to manage the
+		registry of updates. The registry stores the reserved update numbers
+		and the metadata and the file changes of released updates. The
+		location of the registry is set using 'update_registry' in the
+		config.yaml. If it is not set, 'update-registry.json' in the
+		'known_updates_directory' is used.`)
+
+	registryAddCmdUse = "add <update_loc>..."
+	registryAddCmdShortDesc = "Add updates to the registry"
+	registryAddCmdLongDesc = dedent.Dedent(`
+		This command will add the metadata and the file changes of the given
+		update zips to the registry. If an update number was reserved, the
+		entry will be marked as released.`)
+
+	registrySearchCmdUse = "search"
+	registrySearchCmdShortDesc = "Search the registry"
+	registrySearchCmdLongDesc = dedent.Dedent(`
+		This command will list the updates in the registry which match all
+		given flags. All updates are listed if no flags are given.
+		Ex: wum-uc registry search --file repository/components/plugins/foo.jar`)
+
+	registryExportCmdUse = "export [<file>]"
+	registryExportCmdShortDesc = "Export the registry to CSV"
+	registryExportCmdLongDesc = dedent.Dedent(`
+		This command will export all updates in the registry in CSV format.
+		If a file is not given, the CSV will be printed to the console.`)
+)
+
+// Values used to search the registry.
+var registryQuery update.RegistryQuery
+
+// registryCmd represents the registry command.
+var registryCmd = &cobra.Command{
+	Use: registryCmdUse,
+	Short: registryCmdShortDesc,
+	Long: registryCmdLongDesc,
+}
+
+// registryAddCmd represents the registry add command.
+var registryAddCmd = &cobra.Command{
+	Use: registryAddCmdUse,
+	Short: registryAddCmdShortDesc,
+	Long: registryAddCmdLongDesc,
+	Run: initializeRegistryAddCommand,
+}
+
+// registrySearchCmd represents the registry search command.
+var registrySearchCmd = &cobra.Command{
+	Use: registrySearchCmdUse,
+	Short: registrySearchCmdShortDesc,
+	Long: registrySearchCmdLongDesc,
+	Run: initializeRegistrySearchCommand,
+}
+
+// registryExportCmd represents the registry export command.
+var registryExportCmd = &cobra.Command{
+	Use: registryExportCmdUse,
+	Short: registryExportCmdShortDesc,
+	Long: registryExportCmdLongDesc,
+	Run: initializeRegistryExportCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(registryCmd)
+
+	registryCmd.PersistentFlags().String("registry", "", "Location of the registry file")
+	viper.BindPFlag(constant.UPDATE_REGISTRY, registryCmd.PersistentFlags().Lookup("registry"))
+
+	for _, command := range []*cobra.Command{registryAddCmd, registrySearchCmd, registryExportCmd} {
+		registryCmd.AddCommand(command)
+		command.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+		command.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+	}
+
+	registrySearchCmd.Flags().StringVar(&registryQuery.JiraKey, "jira", "", "JIRA key fixed by the update")
+	registrySearchCmd.Flags().StringVar(&registryQuery.File, "file", "", "File added, removed or modified by the update")
+	registrySearchCmd.Flags().StringVar(&registryQuery.PlatformVersion, "platform", "", "Platform version of the update")
+}
+
+// This function will return the location of the registry using the configs.
+func getRegistryOptions() update.RegistryOptions {
+	return update.RegistryOptions{
+		RegistryPath: viper.GetString(constant.UPDATE_REGISTRY),
+		KnownUpdatesDirectory: viper.GetString(constant.KNOWN_UPDATES_DIRECTORY),
+	}
+}
+
+// This function will be called when the registry add command is called.
+func initializeRegistryAddCommand(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc registry add --help' to view help."))
+	}
+	setLogLevel()
+	logger.Debug("[registry add] command called")
+
+	for _, updateZipPath := range args {
+		result, err := update.AddToRegistry(getRegistryOptions(), updateZipPath)
+		handleUpdateErrorAndExit(err)
+		printResult(result)
+	}
+}
+
+// This function will be called when the registry search command is called.
+func initializeRegistrySearchCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc registry search --help' to view help."))
+	}
+	setLogLevel()
+	logger.Debug("[registry search] command called")
+
+	entries, err := update.SearchRegistry(getRegistryOptions(), registryQuery)
+	handleUpdateErrorAndExit(err)
+	if len(entries) == 0 {
+		util.PrintInfo("No matching updates found in the registry.")
+		return
+	}
+	entriesTable := tablewriter.NewWriter(os.Stdout)
+	entriesTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	entriesTable.SetHeader([]string{"Platform", "Number", "Status", "Applies To", "Bug Fixes"})
+	for _, entry := range entries {
+		entriesTable.Append([]string{entry.PlatformVersion, entry.UpdateNumber, entry.Status, entry.AppliesTo,
+			strings.Join(entry.GetJiraKeys(), ", ")})
+	}
+	entriesTable.Render()
+	util.PrintInfo(fmt.Sprintf("%d matching update(s) found.", len(entries)))
+}
+
+// This function will be called when the registry export command is called.
+func initializeRegistryExportCommand(cmd *cobra.Command, args []string) {
+	setLogLevel()
+	logger.Debug("[registry export] command called")
+
+	var writer io.Writer
+	switch len(args) {
+	case 0:
+		writer = os.Stdout
+	case 1:
+		file, err := os.Create(args[0])
+		util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while creating '%s'.", args[0]))
+		defer file.Close()
+		writer = file
+	default:
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc registry export --help' to view help."))
+	}
+	err := update.ExportRegistry(getRegistryOptions(), writer)
+	handleUpdateErrorAndExit(err)
+	if len(args) == 1 {
+		util.PrintInfo(fmt.Sprintf("Registry exported to '%s'.", args[0]))
+	}
+}
@@ -3,16 +3,20 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/ian-kent/go-log/layout"
 	"github.com/ian-kent/go-log/levels"
 	"github.com/ian-kent/go-log/log"
 	"github.com/renstrom/dedent"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/pkg/update"
 	"github.com/wso2/wum-uc/util"
 )
 
@@ -29,6 +33,12 @@ var (
 
 var cfgFile string
 
+// Config values read from the config file and the selected profile. These are used to find the source of the values.
+var (
+	fileConfig *viper.Viper
+	profileConfig *viper.Viper
+)
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use: "wum-uc",
@@ -47,37 +57,55 @@ func Execute() {
 
 func init() {
 	cobra.OnInitialize(initConfig)
+
+	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Location of the config file (default is ./config.yaml or $HOME/.wum-uc/config.yaml)")
+	RootCmd.PersistentFlags().String("profile", "", "Name of the profile in the config file which should be used")
+	viper.BindPFlag(constant.PROFILE, RootCmd.PersistentFlags().Lookup("profile"))
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	setLogLevel()
+	setDefaultValues()
+
 	if cfgFile != "" {
 		// enable ability to specify config file via flag
 		viper.SetConfigFile(cfgFile)
+	} else {
+		viper.SetConfigName("config") // name of config file (without extension)
+		viper.AddConfigPath(".")
+		viper.AddConfigPath("$HOME/.wum-uc")
 	}
-
-	setDefaultValues()
-
-	viper.SetConfigName("config") // name of config file (without extension)
-	viper.AddConfigPath(".")
-	viper.AddConfigPath("$HOME/.wum-uc")
-	//viper.AutomaticEnv()
+	setEnvironmentVariableOverrides()
 
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err == nil {
 		logger.Debug(fmt.Sprintf("Config file found: %v", viper.ConfigFileUsed()))
+		fileConfig = viper.New()
+		fileConfig.SetConfigFile(viper.ConfigFileUsed())
+		fileConfig.ReadInConfig()
+	} else if cfgFile != "" {
+		util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while reading the config file '%s'.", cfgFile))
 	} else {
 		logger.Debug("Config file not found.")
 	}
 
+	err := applyProfile(viper.GetString(constant.PROFILE))
+	if err != nil {
+		util.HandleErrorAndExit(err)
+	}
+
 	logger.Debug(fmt.Sprintf("PATH_SEPARATOR: %s", constant.PATH_SEPARATOR))
 	logger.Debug("Config Values: ---------------------------")
 	logger.Debug(fmt.Sprintf("%s: %s", constant.CHECK_MD5_DISABLED, viper.GetString(constant.CHECK_MD5_DISABLED)))
-	logger.Debug(fmt.Sprintf("%s: %s", constant.RESOURCE_FILES_MANDATORY, viper.GetStringSlice(constant.RESOURCE_FILES_MANDATORY)))
-	logger.Debug(fmt.Sprintf("%s: %s", constant.RESOURCE_FILES_OPTIONAL, viper.GetStringSlice(constant.RESOURCE_FILES_OPTIONAL)))
-	logger.Debug(fmt.Sprintf("%s: %s", constant.RESOURCE_FILES_SKIP, viper.GetStringSlice(constant.RESOURCE_FILES_SKIP)))
-	logger.Debug(fmt.Sprintf("%s: %s", constant.PLATFORM_VERSIONS, viper.GetStringMapString(constant.PLATFORM_VERSIONS)))
+	logger.Debug(fmt.Sprintf("%s: %s", constant.RESOURCE_FILES_MANDATORY, getStringSliceConfig(constant.RESOURCE_FILES_MANDATORY)))
+	logger.Debug(fmt.Sprintf("%s: %s", constant.RESOURCE_FILES_OPTIONAL, getStringSliceConfig(constant.RESOURCE_FILES_OPTIONAL)))
+	logger.Debug(fmt.Sprintf("%s: %s", constant.RESOURCE_FILES_SKIP, getStringSliceConfig(constant.RESOURCE_FILES_SKIP)))
+	// Raw values are logged because invalid values are reported by the commands which use them
+	logger.Debug(fmt.Sprintf("%s: %v", constant.RESOURCE_FILES_PRODUCTS, viper.Get(constant.RESOURCE_FILES_PRODUCTS)))
+	logger.Debug(fmt.Sprintf("%s: %v", constant.PLATFORM_VERSIONS, viper.Get(constant.PLATFORM_VERSIONS)))
+	logger.Debug(fmt.Sprintf("%s: %v", constant.PRODUCT_CATALOG, viper.Get(constant.PRODUCT_CATALOG)))
+	logger.Debug(fmt.Sprintf("%s: %s", constant.PROFILE, viper.GetString(constant.PROFILE)))
 	logger.Debug("-----------------------------------------")
 }
 
@@ -107,4 +135,174 @@ func setDefaultValues() {
 	viper.SetDefault(constant.RESOURCE_FILES_OPTIONAL, util.ResourceFiles_Optional)
 	viper.SetDefault(constant.RESOURCE_FILES_SKIP, util.ResourceFiles_Skip)
 	viper.SetDefault(constant.PLATFORM_VERSIONS, util.PlatformVersions)
+	viper.SetDefault(constant.PRODUCT_CATALOG, util.ProductCatalog)
+}
+
+//This function will allow overriding each config using an environment variable. Name of the environment variable is
+// the config key in upper case with the 'WUMUC_' prefix, and dots and dashes replaced by underscores.
+// Ex: WUMUC_RESOURCE_FILES_SKIP overrides resource_files.skip
+func setEnvironmentVariableOverrides() {
+	viper.SetEnvPrefix(constant.ENV_VARIABLE_PREFIX)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+}
+
+//This function will return the name of the environment variable which overrides the given config key.
+func getEnvironmentVariableName(key string) string {
+	return strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(constant.ENV_VARIABLE_PREFIX + "_" + key))
+}
+
+//This function will return the value of the given config as a list. Lists given using environment variables are
+// separated by commas. Ex: WUMUC_RESOURCE_FILES_SKIP="README.txt,*.md"
+func getStringSliceConfig(key string) []string {
+	value, isString := viper.Get(key).(string)
+	if !isString {
+		return viper.GetStringSlice(key)
+	}
+	values := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+//This function will merge the values of the given profile in the config file with the values in the config file. Values
+// of the profile are used if a value is available in both places. Maps such as resource_files are merged key by key
+// and lists are replaced.
+func applyProfile(profileName string) error {
+	if len(profileName) == 0 {
+		return nil
+	}
+	profileKey := constant.PROFILES + "." + profileName
+	profileValues := viper.GetStringMap(profileKey)
+	if fileConfig == nil || len(profileValues) == 0 {
+		return errors.New(fmt.Sprintf("Profile '%s' not found in the config file. Add it to the '%s' section of the "+
+			"config file.", profileName, strings.ToLower(constant.PROFILES)))
+	}
+	profileConfig = viper.Sub(profileKey)
+	logger.Debug(fmt.Sprintf("Using profile: %s", profileName))
+	return viper.MergeConfigMap(profileValues)
+}
+
+//This function will return the source of the effective value of the given config key. Maps which are set in both the
+// config file and the profile are merged, so both sources are returned for them.
+func getConfigSource(key string) string {
+	if len(os.Getenv(getEnvironmentVariableName(key))) > 0 {
+		return fmt.Sprintf("environment variable %s", getEnvironmentVariableName(key))
+	}
+	isInFile := fileConfig != nil && fileConfig.IsSet(key)
+	if profileConfig != nil && profileConfig.IsSet(key) {
+		profileSource := fmt.Sprintf("profile '%s'", viper.GetString(constant.PROFILE))
+		if _, isMap := profileConfig.Get(key).(map[string]interface{}); isMap && isInFile {
+			return fmt.Sprintf("config file '%s', %s", fileConfig.ConfigFileUsed(), profileSource)
+		}
+		return profileSource
+	}
+	if isInFile {
+		return fmt.Sprintf("config file '%s'", fileConfig.ConfigFileUsed())
+	}
+	if viper.IsSet(key) {
+		return "default"
+	}
+	return "not set"
+}
+
+//This function will return the platform versions. Keys of the returned map are platform versions and values are
+// platform names. This function will exit if the value is invalid. Ex: invalid JSON in WUMUC_PLATFORM_VERSIONS
+func getPlatformVersions() map[string]string {
+	platformVersions, err := parsePlatformVersions(viper.Get(constant.PLATFORM_VERSIONS))
+	util.HandleErrorAndExit(err, fmt.Sprintf("'%s' set using the %s should be a map of platform versions to "+
+		"platform names.", strings.ToLower(constant.PLATFORM_VERSIONS), getConfigSource(constant.PLATFORM_VERSIONS)))
+	return platformVersions
+}
+
+//This function will return the product catalog. Keys of the returned map are product names and values are maps of
+// product versions to platform versions. This function will exit if the value is invalid.
+func getProductCatalog() map[string]map[string]string {
+	productCatalog, err := parseProductCatalog(viper.Get(constant.PRODUCT_CATALOG))
+	util.HandleErrorAndExit(err, fmt.Sprintf("'%s' set using the %s should be a map of product names to product "+
+		"versions.", strings.ToLower(constant.PRODUCT_CATALOG), getConfigSource(constant.PRODUCT_CATALOG)))
+	return productCatalog
+}
+
+//This function will convert the given platform versions. Values given using environment variables are parsed as JSON.
+func parsePlatformVersions(value interface{}) (map[string]string, error) {
+	if value == nil {
+		return make(map[string]string), nil
+	}
+	return cast.ToStringMapStringE(value)
+}
+
+//This function will convert the given product catalog. Values given using environment variables are parsed as JSON.
+func parseProductCatalog(value interface{}) (map[string]map[string]string, error) {
+	productCatalog := make(map[string]map[string]string)
+	if value == nil {
+		return productCatalog, nil
+	}
+	products, err := cast.ToStringMapE(value)
+	if err != nil {
+		return nil, err
+	}
+	for productName, versions := range products {
+		productCatalog[productName], err = cast.ToStringMapStringE(versions)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Versions of '%s' are invalid. %v", productName, err))
+		}
+	}
+	return productCatalog, nil
+}
+
+//This function will return the resource files configured in the config file.
+func getResourceFiles() update.ResourceFiles {
+	return update.ResourceFiles{
+		Mandatory: getStringSliceConfig(constant.RESOURCE_FILES_MANDATORY),
+		Optional: getStringSliceConfig(constant.RESOURCE_FILES_OPTIONAL),
+		Skip: getStringSliceConfig(constant.RESOURCE_FILES_SKIP),
+		Products: getProductResourceFiles(),
+	}
+}
+
+//This function will return the resource files configured for each product. Keys of the returned map are product names
+// or product names and versions. This function will exit if the value is invalid.
+func getProductResourceFiles() map[string]update.ResourceFiles {
+	productResourceFiles := make(map[string]update.ResourceFiles)
+	if !viper.IsSet(constant.RESOURCE_FILES_PRODUCTS) {
+		return productResourceFiles
+	}
+	products, err := cast.ToStringMapE(viper.Get(constant.RESOURCE_FILES_PRODUCTS))
+	util.HandleErrorAndExit(err, fmt.Sprintf("'%s' set using the %s should be a map of products to resource files.",
+		strings.ToLower(constant.RESOURCE_FILES_PRODUCTS), getConfigSource(constant.RESOURCE_FILES_PRODUCTS)))
+	for product, value := range products {
+		rules := cast.ToStringMap(value)
+		productResourceFiles[product] = update.ResourceFiles{
+			Mandatory: cast.ToStringSlice(rules[strings.ToLower(constant.MANDATORY)]),
+			Optional: cast.ToStringSlice(rules[strings.ToLower(constant.OPTIONAL)]),
+			Skip: cast.ToStringSlice(rules[strings.ToLower(constant.SKIP)]),
+		}
+	}
+	return productResourceFiles
+}
+
+//This function will print the messages and the warnings in the given result.
+func printResult(result *update.Result) {
+	if result == nil {
+		return
+	}
+	for _, message := range result.Messages {
+		util.PrintInfo(message)
+	}
+	for _, warning := range result.Warnings {
+		util.PrintWarning(warning)
+	}
+}
+
+//This function will print the given error and exit. If the error belongs to a known failure class, the command which
+// explains the error code is printed as well.
+func handleUpdateErrorAndExit(err error) {
+	if code := update.GetErrorCode(err); len(code) > 0 {
+		err = errors.New(fmt.Sprintf("%s\nRun 'wum-uc explain %s' to view the cause and the fix.", err.Error(), code))
+	}
+	util.HandleErrorAndExit(err)
 }
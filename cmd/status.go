@@ -0,0 +1,107 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/renstrom/dedent"
+	"github.com/spf13/cobra"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Values used to print help command.
+var (
+	statusCmdUse = "status <carbon_home>"
+	statusCmdShortDesc = "Show the updates applied to a distribution"
+	statusCmdLongDesc = dedent.Dedent(`
+		This command will list the updates which were applied to the
+		extracted distribution at the given location using the apply
+		command. It will also check whether the files in the distribution
+		still match the files recorded when applying the updates.`)
+)
+
+// statusCmd represents the status command.
+var statusCmd = &cobra.Command{
+	Use: statusCmdUse,
+	Short: statusCmdShortDesc,
+	Long: statusCmdLongDesc,
+	Run: initializeStatusCommand,
+}
+
+// This function will be called first and this will add flags to the command.
+func init() {
+	RootCmd.AddCommand(statusCmd)
+
+	statusCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
+	statusCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+}
+
+// This function will be called when the status command is called.
+func initializeStatusCommand(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc status --help' to view help."))
+	}
+	showStatus(args[0])
+}
+
+// This function will print the applied updates and the files which do not match the ledger.
+func showStatus(carbonHome string) {
+	setLogLevel()
+	logger.Debug("[status] command called")
+
+	exists, err := util.IsDirectoryExists(carbonHome)
+	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while checking '%s'", carbonHome))
+	if !exists {
+		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Directory does not exist at '%s'. Distribution must be an extracted directory.", carbonHome)))
+	}
+
+	ledger, err := util.LoadLedger(carbonHome)
+	util.HandleErrorAndExit(err, "Error occurred while reading the applied updates.")
+	if len(ledger.AppliedUpdates) == 0 {
+		util.PrintInfo(fmt.Sprintf("No updates have been applied to '%s'.", carbonHome))
+		return
+	}
+
+	// Print the applied updates
+	updatesTable := tablewriter.NewWriter(os.Stdout)
+	updatesTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	updatesTable.SetHeader([]string{"Update", "Number", "Applied Time", "Files", "Tool Version"})
+	for _, appliedUpdate := range ledger.AppliedUpdates {
+		updatesTable.Append([]string{appliedUpdate.UpdateName, appliedUpdate.UpdateNumber, appliedUpdate.AppliedTime,
+			strconv.Itoa(len(appliedUpdate.Files)), appliedUpdate.ToolVersion})
+	}
+	updatesTable.Render()
+
+	// Print the files which do not match the ledger
+	driftedFiles, err := util.FindDrift(carbonHome, ledger)
+	util.HandleErrorAndExit(err, "Error occurred while checking the files in the distribution.")
+	if len(driftedFiles) == 0 {
+		util.PrintInfo("All files match the applied updates.")
+		return
+	}
+	fmt.Println()
+	util.PrintWarning("Following files do not match the applied updates.")
+	driftTable := tablewriter.NewWriter(os.Stdout)
+	driftTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	driftTable.SetHeader([]string{"File", "Last Update", "Expected MD5", "Actual MD5"})
+	for _, driftedFile := range driftedFiles {
+		driftTable.Append([]string{driftedFile.Path, driftedFile.UpdateName, getMD5OrStatus(driftedFile.ExpectedMd5),
+			getMD5OrStatus(driftedFile.ActualMd5)})
+	}
+	driftTable.Render()
+	util.HandleErrorAndExit(errors.New(fmt.Sprintf("%d file(s) do not match the applied updates.", len(driftedFiles))))
+}
+
+// This function will return the given md5 hash or a status text if the hash is empty which means the file does not
+// exist.
+func getMD5OrStatus(md5Hash string) string {
+	if len(md5Hash) == 0 {
+		return "(not found)"
+	}
+	return md5Hash
+}
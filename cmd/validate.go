@@ -3,32 +3,57 @@
 package cmd
 
 import (
-	"archive/zip"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
-	"path/filepath"
-	"regexp"
-	"strings"
+	"strconv"
 
+	"github.com/olekukonko/tablewriter"
 	"github.com/renstrom/dedent"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/pkg/update"
 	"github.com/wso2/wum-uc/util"
-	"gopkg.in/yaml.v2"
 )
 
 var (
-	validateCmdUse = "validate <update_loc> <dist_loc>"
+	validateCmdUse = "validate <update_loc|updates_dir|update_dir> <dist_loc>"
 	validateCmdShortDesc = "Validate update zip"
 	validateCmdLongDesc = dedent.Dedent(`
 		This command will validate the given update zip. Files will be
 		matched against the given distribution. This will also validate
-		the structure of the update-descriptor.yaml file as well.`)
+		the structure of the update-descriptor.yaml file as well. If a
+		directory of known updates is given, 'requires' and 'supersedes'
+		fields will be checked for cycles. If a public key is given, the
+		detached signature of the update zip will be verified as well. If
+		the --json flag is given, the result will be printed as a JSON
+		report. If the --batch flag is given, all update zips in the given
+		directory will be validated concurrently and duplicate update
+		numbers will be reported. If the --source flag is given, the
+		given update directory will be validated before creating the
+		update zip.`)
 )
 
+// Location of the public key which is used to verify the signatures of updates.
+var verificationKeyPath string
+
+// Whether placeholders in the update-descriptor.yaml should be reported as warnings instead of errors.
+var isPlaceholdersAllowed = false
+
+// Whether all updates in a directory should be validated and the number of updates validated concurrently.
+var (
+	isBatchValidation = false
+	batchWorkers = 0
+)
+
+// Whether an update directory should be validated instead of an update zip.
+var isSourceValidation = false
+
+// Location of the .wumucignore file which is used to find junk files in update zips.
+var ignoreFilePath string
+
 // validateCmd represents the validate command
 var validateCmd = &cobra.Command{
 	Use: validateCmdUse,
@@ -43,6 +68,23 @@ func init() {
 
 	validateCmd.Flags().BoolVarP(&isDebugLogsEnabled, "debug", "d", util.EnableDebugLogs, "Enable debug logs")
 	validateCmd.Flags().BoolVarP(&isTraceLogsEnabled, "trace", "t", util.EnableTraceLogs, "Enable trace logs")
+
+	validateCmd.Flags().String("updates-dir", "", "Directory of known updates used to check 'requires' and 'supersedes' cycles")
+	viper.BindPFlag(constant.KNOWN_UPDATES_DIRECTORY, validateCmd.Flags().Lookup("updates-dir"))
+
+	validateCmd.Flags().StringVar(&verificationKeyPath, "verify", "", "Public key used to verify the signature of the update")
+
+	validateCmd.Flags().Bool("json", false, "Print the result as a JSON report")
+	viper.BindPFlag(constant.JSON_OUTPUT, validateCmd.Flags().Lookup("json"))
+
+	validateCmd.Flags().BoolVar(&isBatchValidation, "batch", false, "Validate all update zips in the given directory")
+	validateCmd.Flags().IntVar(&batchWorkers, "workers", 0, "Number of updates validated concurrently in batch mode (default: number of CPUs)")
+
+	validateCmd.Flags().BoolVar(&isSourceValidation, "source", false, "Validate an update directory before creating the update zip")
+
+	validateCmd.Flags().BoolVar(&isPlaceholdersAllowed, "allow-placeholders", false, "Report placeholders in the update-descriptor.yaml as warnings instead of errors")
+
+	validateCmd.Flags().StringVar(&ignoreFilePath, "ignore-file", constant.IGNORE_FILE, "File with the patterns of junk files in update zips. The update directory's file is used with --source")
 }
 
 //This function will be called when the validate command is called.
@@ -50,7 +92,16 @@ func initializeValidateCommand(cmd *cobra.Command, args []string) {
 	if len(args) != 2 {
 		util.HandleErrorAndExit(errors.New("Invalid number of argumants. Run 'wum-uc validate --help' to view help."))
 	}
-	startValidation(args[0], args[1])
+	switch {
+	case isBatchValidation && isSourceValidation:
+		util.HandleErrorAndExit(errors.New("--batch and --source flags cannot be used together."))
+	case isBatchValidation:
+		startBatchValidation(args[0], args[1])
+	case isSourceValidation:
+		startSourceValidation(args[0], args[1])
+	default:
+		startValidation(args[0], args[1])
+	}
 }
 
 //This function will start the validation process.
@@ -60,297 +111,118 @@ func startValidation(updateFilePath, distributionLocation string) {
 	setLogLevel()
 	logger.Debug("validate command called")
 
-	updateFileMap := make(map[string]bool)
-	distributionFileMap := make(map[string]bool)
-
-	//Check whether the update has the zip extension
-	if !strings.HasSuffix(updateFilePath, ".zip") {
-		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Update must be a zip file. Entered file '%s' does not have a zip extension.", updateFilePath)))
-	}
-
-	//Check whether the update file exists
-	exists, err := util.IsFileExists(updateFilePath)
-	util.HandleErrorAndExit(err, "")
-	if !exists {
-		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Entered update file does not exist at '%s'.", updateFilePath)))
-	}
-
-	//Check whether the distribution has the zip extension
-	if !strings.HasSuffix(distributionLocation, ".zip") {
-		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Distribution must be a zip file. Entered file '%s' does not have a zip extension.", distributionLocation)))
-	}
-
-	//Set the product name in viper configs
-	lastIndex := strings.LastIndex(distributionLocation, constant.PATH_SEPARATOR)
-	productName := strings.TrimSuffix(distributionLocation[lastIndex + 1:], ".zip")
-	logger.Debug(fmt.Sprintf("Setting ProductName: %s", productName))
-	viper.Set(constant.PRODUCT_NAME, productName)
-
-	//Check whether the distribution file exists
-	exists, err = util.IsFileExists(distributionLocation)
-	util.HandleErrorAndExit(err, fmt.Sprintf("Error occurred while checking '%s'", distributionLocation))
-	if !exists {
-		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Entered distribution file does not exist at '%s'.", distributionLocation)))
-	}
-
-	//Check update filename
-	locationInfo, err := os.Stat(updateFilePath)
-	util.HandleErrorAndExit(err, "Error occurred while getting the information of update file")
-	match, err := regexp.MatchString(constant.FILENAME_REGEX, locationInfo.Name())
-	if !match {
-		util.HandleErrorAndExit(errors.New(fmt.Sprintf("Update filename '%s' does not match '%s' regular expression.", locationInfo.Name(), constant.FILENAME_REGEX)))
-	}
-
-	//Set the update name in viper configs
-	updateName := strings.TrimSuffix(locationInfo.Name(), ".zip")
-	viper.Set(constant.UPDATE_NAME, updateName)
-
-	//Read the update zip file
-	updateFileMap, updateDescriptor, err := readUpdateZip(updateFilePath)
-	util.HandleErrorAndExit(err)
-	logger.Trace(fmt.Sprintf("updateFileMap: %v\n", updateFileMap))
-
-	//Read the distribution zip file
-	distributionFileMap, err = readDistributionZip(distributionLocation)
-	util.HandleErrorAndExit(err)
-	logger.Trace(fmt.Sprintf("distributionFileMap: %v\n", distributionFileMap))
-
-	//Compare the update with the distribution
-	err = compare(updateFileMap, distributionFileMap, updateDescriptor)
-	util.HandleErrorAndExit(err)
-	util.PrintInfo("'" + updateName + "' validation successfully finished.")
+	result, err := update.Validate(update.ValidateOptions{
+		UpdateFilePath: updateFilePath,
+		DistributionPath: distributionLocation,
+		VerificationKey: getVerificationKeyPath(),
+		KnownUpdatesDirectory: viper.GetString(constant.KNOWN_UPDATES_DIRECTORY),
+		ResourceFiles: getResourceFiles(),
+		ProductCatalog: getProductCatalog(),
+		AllowPlaceholders: isPlaceholdersAllowed,
+		IgnoreFile: ignoreFilePath,
+	})
+	if viper.GetBool(constant.JSON_OUTPUT) {
+		report := update.NewReport(updateFilePath, result, err)
+		printJsonReport(report, report.Valid)
+		return
+	}
+	printResult(result)
+	handleUpdateErrorAndExit(err)
+	util.PrintInfo("'" + result.UpdateName + "' validation successfully finished.")
 }
 
-//This function compares the files in the update and the distribution.
-func compare(updateFileMap, distributionFileMap map[string]bool, updateDescriptor *util.UpdateDescriptor) error {
-	updateName := viper.GetString(constant.UPDATE_NAME)
-	for filePath := range updateFileMap {
-		logger.Debug(fmt.Sprintf("Searching: %s", filePath))
-		_, found := distributionFileMap[filePath]
-		if !found {
-			logger.Debug("Added files: ", updateDescriptor.File_changes.Added_files)
-			isInAddedFiles := util.IsStringIsInSlice(filePath, updateDescriptor.File_changes.Added_files)
-			logger.Debug(fmt.Sprintf("isInAddedFiles: %v", isInAddedFiles))
-			resourceFiles := getResourceFiles()
-			logger.Debug(fmt.Sprintf("resourceFiles: %v", resourceFiles))
-			fileName := strings.TrimPrefix(filePath, updateName + "/")
-			logger.Debug(fmt.Sprintf("fileName: %s", fileName))
-			_, foundInResources := resourceFiles[fileName]
-			logger.Debug(fmt.Sprintf("found in resources: %v", foundInResources))
-			//check
-			if !isInAddedFiles && !foundInResources {
-				return errors.New("File not found in the distribution: '" + filePath + "'. If this is a new file, add an entry to the 'added_files' sections in the '" + constant.UPDATE_DESCRIPTOR_FILE + "' file")
-			} else {
-				logger.Debug("'" + filePath + "' found in added files.")
-			}
-		}
-	}
-	return nil
-}
+//This function will start validating the given update directory.
+func startSourceValidation(updateDirectory, distributionLocation string) {
 
-//This function will read the update zip at the the given location.
-func readUpdateZip(filename string) (map[string]bool, *util.UpdateDescriptor, error) {
-	fileMap := make(map[string]bool)
-	updateDescriptor := util.UpdateDescriptor{}
+	//Set the log level
+	setLogLevel()
+	logger.Debug("validate --source command called")
+
+	result, err := update.ValidateSource(update.SourceOptions{
+		UpdateDirectory: updateDirectory,
+		DistributionPath: distributionLocation,
+		ResourceFiles: getResourceFiles(),
+		ProductCatalog: getProductCatalog(),
+		AllowPlaceholders: isPlaceholdersAllowed,
+	})
+	if viper.GetBool(constant.JSON_OUTPUT) {
+		report := update.NewReport(updateDirectory, result, err)
+		printJsonReport(report, report.Valid)
+		return
+	}
+	printResult(result)
+	handleUpdateErrorAndExit(err)
+	util.PrintInfo(fmt.Sprintf("'%s' validation successfully finished.", updateDirectory))
+}
 
-	isNotAContributionFileFound := false
-	isASecPatch := false
+//This function will start validating all updates in the given directory.
+func startBatchValidation(updatesDirectory, distributionLocation string) {
 
-	// Create a reader out of the zip archive
-	zipReader, err := zip.OpenReader(filename)
-	if err != nil {
-		return nil, nil, err
-	}
-	defer zipReader.Close()
-
-	updateName := viper.GetString(constant.UPDATE_NAME)
-	logger.Debug("updateName:", updateName)
-	// Iterate through each file/dir found in
-	for _, file := range zipReader.Reader.File {
-		name := getFileName(file.FileInfo().Name())
-		if file.FileInfo().IsDir() {
-			logger.Debug(fmt.Sprintf("filepath: %s", file.Name))
-
-			logger.Debug(fmt.Sprintf("filename: %s", name))
-			if name != updateName {
-				logger.Debug("Checking:", name)
-				//Check
-				prefix := filepath.Join(updateName, constant.CARBON_HOME)
-				hasPrefix := strings.HasPrefix(file.Name, prefix)
-				if !hasPrefix {
-					return nil, nil, errors.New("Unknown directory found: '" + file.Name + "'")
-				}
-			}
+	//Set the log level
+	setLogLevel()
+	logger.Debug("validate --batch command called")
+
+	batchReport, err := update.ValidateBatch(update.BatchOptions{
+		UpdatesDirectory: updatesDirectory,
+		DistributionPath: distributionLocation,
+		Workers: batchWorkers,
+		VerificationKey: getVerificationKeyPath(),
+		KnownUpdatesDirectory: viper.GetString(constant.KNOWN_UPDATES_DIRECTORY),
+		ResourceFiles: getResourceFiles(),
+		ProductCatalog: getProductCatalog(),
+		AllowPlaceholders: isPlaceholdersAllowed,
+		IgnoreFile: ignoreFilePath,
+	})
+	handleUpdateErrorAndExit(err)
+	if viper.GetBool(constant.JSON_OUTPUT) {
+		printJsonReport(batchReport, batchReport.Failed == 0)
+		return
+	}
+
+	// Print the pass/fail table
+	reportTable := tablewriter.NewWriter(os.Stdout)
+	reportTable.SetAlignment(tablewriter.ALIGN_LEFT)
+	reportTable.SetHeader([]string{"Update", "Status", "Code", "Details"})
+	for _, report := range batchReport.Updates {
+		if report.Valid {
+			reportTable.Append([]string{report.Update, "PASS", "", strconv.Itoa(len(report.Warnings)) + " warning(s)"})
 		} else {
-			//todo: check for ignored files .gitignore
-			logger.Debug(fmt.Sprintf("file.Name: %s", file.Name))
-			logger.Debug(fmt.Sprintf("file.FileInfo().Name(): %s", name))
-			fullPath := filepath.Join(updateName, name)
-			logger.Debug(fmt.Sprintf("fullPath: %s", fullPath))
-			switch  name{
-			case constant.UPDATE_DESCRIPTOR_FILE:
-				//todo: check for any remaining placeholders
-				data, err := validateFile(file, constant.UPDATE_DESCRIPTOR_FILE, fullPath, updateName)
-				if err != nil {
-					return nil, nil, err
-				}
-				err = yaml.Unmarshal(data, &updateDescriptor)
-				if err != nil {
-					return nil, nil, err
-				}
-				//check
-				err = util.ValidateUpdateDescriptor(&updateDescriptor)
-				if err != nil {
-					return nil, nil, errors.New("'" + constant.UPDATE_DESCRIPTOR_FILE + "' is invalid. " + err.Error())
-				}
-			case constant.LICENSE_FILE:
-				data, err := validateFile(file, constant.LICENSE_FILE, fullPath, updateName)
-				if err != nil {
-					return nil, nil, err
-				}
-				dataString := string(data)
-				if strings.Contains(dataString, "under Apache License 2.0") {
-					isASecPatch = true
-				}
-			case constant.INSTRUCTIONS_FILE:
-				_, err := validateFile(file, constant.INSTRUCTIONS_FILE, fullPath, updateName)
-				if err != nil {
-					return nil, nil, err
-				}
-			case constant.NOT_A_CONTRIBUTION_FILE:
-				isNotAContributionFileFound = true
-				_, err := validateFile(file, constant.NOT_A_CONTRIBUTION_FILE, fullPath, updateName)
-				if err != nil {
-					return nil, nil, err
-				}
-			default:
-				resourceFiles := getResourceFiles()
-				logger.Debug(fmt.Sprintf("resourceFiles: %v", resourceFiles))
-				prefix := filepath.Join(updateName, constant.CARBON_HOME)
-				logger.Debug(fmt.Sprintf("Checking prefix %s in %s", prefix, file.Name))
-				hasPrefix := strings.HasPrefix(file.Name, prefix)
-				_, foundInResources := resourceFiles[ name]
-				logger.Debug(fmt.Sprintf("foundInResources: %v", foundInResources))
-				if !hasPrefix && !foundInResources {
-					return nil, nil, errors.New(fmt.Sprintf("Unknown file found: '%s'.", file.Name))
-				}
-				logger.Debug(fmt.Sprintf("Trimming: %s using %s", file.Name, prefix + constant.PATH_SEPARATOR))
-				relativePath := strings.TrimPrefix(file.Name, prefix + constant.PATH_SEPARATOR)
-				fileMap[relativePath] = false
-			}
+			reportTable.Append([]string{report.Update, "FAIL", string(report.Error.Code), report.Error.Description()})
 		}
 	}
-	if !isASecPatch && !isNotAContributionFileFound {
-		util.PrintWarning("This update is not a security update. But '" + constant.NOT_A_CONTRIBUTION_FILE + "' was not found. Please review and add '" + constant.NOT_A_CONTRIBUTION_FILE + "' file if necessary.")
-	} else if isASecPatch && isNotAContributionFileFound {
-		util.PrintWarning("This update is a security update. But '" + constant.NOT_A_CONTRIBUTION_FILE + "' was found. Please review and remove '" + constant.NOT_A_CONTRIBUTION_FILE + "' file if necessary.")
-	}
-	return fileMap, &updateDescriptor, nil
-}
+	reportTable.Render()
 
-//This function will validate the provided file. If the word 'patch' is found, a warning message is printed.
-func validateFile(file *zip.File, fileName, fullPath, updateName string) ([]byte, error) {
-	logger.Debug(fmt.Sprintf("Validating '%s' at '%s' started.", fileName, fullPath))
-	parent := strings.TrimSuffix(file.Name, getFileName(file.FileInfo().Name()))
-	if file.Name != fullPath {
-		return nil, errors.New(fmt.Sprintf("'%s' found at '%s'. It should be in the '%s' directory.", fileName, parent, updateName))
-	} else {
-		logger.Debug(fmt.Sprintf("'%s' found at '%s'.", fileName, parent))
-	}
-	zippedFile, err := file.Open()
-	if err != nil {
-		logger.Debug(fmt.Sprintf("Error occurred while opening the zip file: %v", err))
-		return nil, err
+	for _, duplicate := range batchReport.Duplicates {
+		util.PrintWarning(fmt.Sprintf("Update number '%s' of platform version '%s' is used by %d updates.",
+			duplicate.UpdateNumber, duplicate.PlatformVersion, len(duplicate.Updates)))
 	}
-	data, err := ioutil.ReadAll(zippedFile)
-	if err != nil {
-		logger.Debug(fmt.Sprintf("Error occurred while reading the zip file: %v", err))
-		return nil, err
+	if batchReport.Total == 0 {
+		util.PrintWarning(fmt.Sprintf("No update zips found in '%s'.", updatesDirectory))
 	}
-	zippedFile.Close()
-
-	dataString := string(data)
-	dataString = util.ProcessString(dataString, "\n", true)
-
-	//check
-	regex, err := regexp.Compile(constant.PATCH_REGEX)
-	allMatches := regex.FindAllStringSubmatch(dataString, -1)
-	logger.Debug(fmt.Sprintf("All matches: %v", allMatches))
-	isPatchWordFound := false
-	if len(allMatches) > 0 {
-		isPatchWordFound = true
+	summary := fmt.Sprintf("%d update(s) validated. %d passed, %d failed.", batchReport.Total, batchReport.Passed,
+		batchReport.Failed)
+	if batchReport.Failed > 0 {
+		util.HandleErrorAndExit(errors.New(summary))
 	}
-	if isPatchWordFound {
-		util.PrintWarning(fmt.Sprintf("'%v' file contains the word 'patch' in following lines. Please review and change it to 'update' if possible.", fileName))
-		for i, line := range allMatches {
-			util.PrintInfo(fmt.Sprintf("Matching Line #%d - %v", i + 1, line[0]))
-		}
-		fmt.Println()
-	}
-
-	// Check whether the all placeholders are removed
-	contains := strings.Contains(dataString, constant.UPDATE_NO_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.UPDATE_NO_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-	contains = strings.Contains(dataString, constant.PLATFORM_NAME_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.PLATFORM_NAME_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-	contains = strings.Contains(dataString, constant.PLATFORM_VERSION_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.PLATFORM_VERSION_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-	contains = strings.Contains(dataString, constant.APPLIES_TO_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.APPLIES_TO_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-	contains = strings.Contains(dataString, constant.DESCRIPTION_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.DESCRIPTION_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-	contains = strings.Contains(dataString, constant.JIRA_KEY_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.JIRA_KEY_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-	contains = strings.Contains(dataString, constant.JIRA_SUMMARY_DEFAULT)
-	if contains {
-		util.PrintWarning(fmt.Sprintf("Please add the correct value for '%v' in the '%v' file.", constant.JIRA_SUMMARY_DEFAULT, constant.UPDATE_DESCRIPTOR_FILE))
-	}
-
-	logger.Debug(fmt.Sprintf("Validating '%s' finished.", fileName))
-	return data, nil
+	util.PrintInfo(summary)
 }
 
-//This function reads the product distribution at the given location.
-func readDistributionZip(filename string) (map[string]bool, error) {
-	fileMap := make(map[string]bool)
-	// Create a reader out of the zip archive
-	zipReader, err := zip.OpenReader(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer zipReader.Close()
-
-	productName := viper.GetString(constant.PRODUCT_NAME)
-	logger.Debug(fmt.Sprintf("productName: %s", productName))
-	// Iterate through each file/dir found in
-	for _, file := range zipReader.Reader.File {
-		logger.Trace(file.Name)
-		relativePath := strings.TrimPrefix(file.Name, productName + "/")
-		if !file.FileInfo().IsDir() {
-			fileMap[relativePath] = false
-		}
+//This function will print the given report as JSON. If the report is not valid, the process will exit with a non zero
+// exit code.
+func printJsonReport(report interface{}, isValid bool) {
+	data, err := json.MarshalIndent(report, "", "  ")
+	util.HandleErrorAndExit(err, "Error occurred while marshalling the report.")
+	fmt.Println(string(data))
+	if !isValid {
+		os.Exit(1)
 	}
-	return fileMap, nil
 }
 
-//When reading zip files in windows, file.FileInfo().Name() does not return the filename correctly
-// (where file *zip.File) To fix this issue, this function was added.
-func getFileName(filename string) string {
-	filename = filepath.ToSlash(filename)
-	if lastIndex := strings.LastIndex(filename, "/"); lastIndex > -1 {
-		filename = filename[lastIndex + 1:]
+//This function will return the location of the public key given using the --verify flag. If the flag is not given, the
+//key configured in the config file is returned.
+func getVerificationKeyPath() string {
+	if len(verificationKeyPath) > 0 {
+		return verificationKeyPath
 	}
-	return filename
+	return viper.GetString(constant.VERIFICATION_KEY)
 }
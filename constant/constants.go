@@ -19,20 +19,58 @@ const (
 	LICENSE_FILE = "LICENSE.txt"
 	NOT_A_CONTRIBUTION_FILE = "NOT_A_CONTRIBUTION.txt"
 	INSTRUCTIONS_FILE = "instructions.txt"
+	//Optional structured version of the instructions
+	INSTRUCTIONS_YAML_FILE = "instructions.yaml"
+	//Structured diffs of the configuration files which is generated by create
+	CONF_DIFFS_FILE = "conf-diffs.yaml"
 	UPDATE_DESCRIPTOR_FILE = "update-descriptor.yaml"
+	//File in the update directory which contains the patterns of the files which should not be added to the update
+	IGNORE_FILE = ".wumucignore"
 
-	//Temporary directory to copy files before creating the new zip
-	TEMP_DIR = "temp"
+	//Prefix of the staging directory which is created in the OS temp location to copy files before creating the new zip
+	TEMP_DIR_PREFIX = "wum-uc-"
+	//Environment variable which is used to set the timestamp of the entries in the update zip
+	SOURCE_DATE_EPOCH = "SOURCE_DATE_EPOCH"
 	//This is used to store carbon.home string
 	CARBON_HOME = "carbon.home"
 	//Prefix of the update file and the root directory of the update zip
 	UPDATE_NAME_PREFIX = "WSO2-CARBON-UPDATE"
 
+	//Location of the record of applied updates inside CARBON_HOME
+	LEDGER_DIRECTORY = "updates"
+	LEDGER_FILE = "applied-updates.yaml"
+
+	//Registry of the released and reserved update numbers
+	REGISTRY_FILE = "update-registry.json"
+	LOCK_FILE_EXTENSION = ".lock"
+	REGISTRY_STATUS_RESERVED = "reserved"
+	REGISTRY_STATUS_RELEASED = "released"
+
+	//Constants used to sign updates
+	SIGNATURE_EXTENSION = ".sig"
+	KEY_TYPE_ED25519 = "ed25519"
+	KEY_TYPE_RSA = "rsa"
+	RSA_KEY_SIZE = 4096
+	PRIVATE_KEY_EXTENSION = ".pem"
+	PUBLIC_KEY_EXTENSION = ".pub.pem"
+
 	//Constants to store configs in viper
 	DISTRIBUTION_ROOT = "DISTRIBUTION_ROOT"
-	UPDATE_ROOT = "UPDATE_ROOT"
-	UPDATE_NAME = "_UPDATE_NAME"
-	PRODUCT_NAME = "_PRODUCT_NAME"
+	KNOWN_UPDATES_DIRECTORY = "KNOWN_UPDATES_DIRECTORY"
+	SIGNING_KEY = "SIGNING_KEY"
+	OUTPUT_LOCATION = "OUTPUT_LOCATION"
+	VERIFICATION_KEY = "VERIFICATION_KEY"
+	UPDATE_REGISTRY = "UPDATE_REGISTRY"
+
+	//Supported versions of the update-descriptor.yaml. Descriptors without a 'descriptor_version' are version 1.
+	DESCRIPTOR_VERSION_1 = "1"
+	DESCRIPTOR_VERSION_2 = "2"
+
+	//Security severities which can be used in version 2 descriptors
+	SECURITY_SEVERITY_CRITICAL = "critical"
+	SECURITY_SEVERITY_HIGH = "high"
+	SECURITY_SEVERITY_MEDIUM = "medium"
+	SECURITY_SEVERITY_LOW = "low"
 
 	UPDATE_NUMBER_REGEX = "^\\d{4}$"
 	KERNEL_VERSION_REGEX = "^\\d+\\.\\d+\\.\\d+$"
@@ -44,17 +82,30 @@ const (
 	REENTER = 3
 
 	SAMPLE = "SAMPLE"
+	INTERACTIVE = "INTERACTIVE"
 	CHECK_MD5_DISABLED = "CHECK_MD5_DISABLED"
+	JSON_OUTPUT = "JSON_OUTPUT"
 	//resource_files
 	RESOURCE_FILES = "RESOURCE_FILES"
 	MANDATORY = "MANDATORY"
 	OPTIONAL = "OPTIONAL"
 	SKIP = "SKIP"
+	PRODUCTS = "PRODUCTS"
 	RESOURCE_FILES_MANDATORY = RESOURCE_FILES + "." + MANDATORY
 	RESOURCE_FILES_OPTIONAL = RESOURCE_FILES + "." + OPTIONAL
 	RESOURCE_FILES_SKIP = RESOURCE_FILES + "." + SKIP
+	//Resource files of each product - product name or product name and version -> mandatory, optional, skip
+	RESOURCE_FILES_PRODUCTS = RESOURCE_FILES + "." + PRODUCTS
 
 	PLATFORM_VERSIONS = "PLATFORM_VERSIONS"
+	//product_catalog - product name -> product version -> platform version
+	PRODUCT_CATALOG = "PRODUCT_CATALOG"
+	//profiles - profile name -> config values which override the values in the config file
+	PROFILES = "PROFILES"
+	//Name of the profile which is used
+	PROFILE = "PROFILE"
+	//Prefix of the environment variables which override the configs. Ex: WUMUC_RESOURCE_FILES_SKIP
+	ENV_VARIABLE_PREFIX = "WUMUC"
 
 	PATCH_ID_REGEX = "WSO2-CARBON-PATCH-(\\d+\\.\\d+\\.\\d+)-(\\d{4})"
 	APPLIES_TO_REGEX = "(?s)Applies To.*?:(.*)Associated JIRA|Applies To.*?:(.*)DESCRIPTION"
@@ -74,4 +125,9 @@ const (
 	JIRA_KEY_DEFAULT = "ADD_JIRA_KEY_HERE"
 	JIRA_NA = "N/A"
 	JIRA_SUMMARY_DEFAULT = "ADD_JIRA_SUMMARY_HERE"
+
+	//Editor which is used to get multi-line inputs from the user
+	EDITOR_ENV_VARIABLE = "EDITOR"
+	DEFAULT_EDITOR = "vi"
+	DEFAULT_EDITOR_WINDOWS = "notepad"
 )
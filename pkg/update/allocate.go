@@ -0,0 +1,225 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// This is used to get the platform version and the update number from the filename of an update zip
+var updateFilenameRegex = regexp.MustCompile(`^WSO2-CARBON-UPDATE-(\d+\.\d+\.\d+)-(\d{4})\.zip$`)
+
+// struct which is used to pass the values required to allocate the next update number
+type AllocateOptions struct {
+	// Platform version of the update. Update numbers are allocated separately for each platform version.
+	PlatformVersion       string
+	// Directory which contains the released update zips
+	KnownUpdatesDirectory string
+	// Location of the registry file. If this is empty, the registry in the KnownUpdatesDirectory is used.
+	RegistryPath          string
+	// Name which is stored in the registry with the reserved number. If this is empty, the current user is used.
+	ReservedBy            string
+}
+
+// AllocateUpdateNumber will find the highest update number used for the given platform version in the directory of
+// known updates and in the registry, and reserve the next number in the registry. The registry is locked using a
+// lock file while the number is allocated so that two developers cannot reserve the same number.
+func AllocateUpdateNumber(opts AllocateOptions) (string, error) {
+	if err := util.ValidatePlatformVersion(opts.PlatformVersion); err != nil {
+		return "", newErrorWithCode(opAllocate, CodeDescriptorInvalid, err, "")
+	}
+	registryPath, err := getConfiguredRegistryPath(opAllocate, opts.RegistryPath, opts.KnownUpdatesDirectory)
+	if err != nil {
+		return "", err
+	}
+	logger.Debug(fmt.Sprintf("registryPath: %s", registryPath))
+
+	releaseLock, err := lockRegistry(opAllocate, registryPath)
+	if err != nil {
+		return "", err
+	}
+	defer releaseLock()
+
+	registry, err := loadRegistry(registryPath)
+	if err != nil {
+		return "", newError(opAllocate, err, fmt.Sprintf("Error occurred while reading the registry '%s'.", registryPath))
+	}
+	highestNumber := 0
+	for _, entry := range registry.Updates {
+		if entry.PlatformVersion != opts.PlatformVersion {
+			continue
+		}
+		if number, err := strconv.Atoi(entry.UpdateNumber); err == nil && number > highestNumber {
+			highestNumber = number
+		}
+	}
+	if len(opts.KnownUpdatesDirectory) > 0 {
+		number, err := getHighestKnownUpdateNumber(opts.KnownUpdatesDirectory, opts.PlatformVersion)
+		if err != nil {
+			return "", newError(opAllocate, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.KnownUpdatesDirectory))
+		}
+		if number > highestNumber {
+			highestNumber = number
+		}
+	}
+	logger.Debug(fmt.Sprintf("Highest update number of %s: %d", opts.PlatformVersion, highestNumber))
+	if highestNumber >= 9999 {
+		return "", newError(opAllocate, nil, fmt.Sprintf("All update numbers of platform version %s are used.",
+			opts.PlatformVersion))
+	}
+
+	updateNumber := fmt.Sprintf("%04d", highestNumber + 1)
+	reservedBy := opts.ReservedBy
+	if len(reservedBy) == 0 {
+		if currentUser, err := user.Current(); err == nil {
+			reservedBy = currentUser.Username
+		}
+	}
+	registry.Updates = append(registry.Updates, RegistryEntry{
+		PlatformVersion: opts.PlatformVersion,
+		UpdateNumber: updateNumber,
+		Status: constant.REGISTRY_STATUS_RESERVED,
+		ReservedBy: reservedBy,
+		ReservedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	err = saveRegistry(registryPath, registry)
+	if err != nil {
+		return "", newError(opAllocate, err, fmt.Sprintf("Error occurred while saving the registry '%s'.", registryPath))
+	}
+	return updateNumber, nil
+}
+
+// This function will return the highest update number of the given platform version in the given directory of known
+// updates. Update numbers are read from the filenames of the update zips.
+func getHighestKnownUpdateNumber(knownUpdatesDirectory, platformVersion string) (int, error) {
+	files, err := ioutil.ReadDir(knownUpdatesDirectory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	highestNumber := 0
+	for _, file := range files {
+		matches := updateFilenameRegex.FindStringSubmatch(file.Name())
+		if file.IsDir() || matches == nil || matches[1] != platformVersion {
+			continue
+		}
+		if number, err := strconv.Atoi(matches[2]); err == nil && number > highestNumber {
+			highestNumber = number
+		}
+	}
+	return highestNumber, nil
+}
+
+// struct which is used to undo the allocation of an update number for an update directory
+type numberAllocation struct {
+	// Location of the update-descriptor.yaml and its content before setting the update number
+	updateDescriptorPath string
+	originalData         []byte
+	registryPath         string
+	platformVersion      string
+	updateNumber         string
+}
+
+// This function will allocate the next update number and save it in the update-descriptor.yaml of the given update
+// directory if it does not have a valid update number. Only the update_number field of the file is changed. The
+// returned allocation can be used to undo the changes if the update is not created. nil is returned if a number is not
+// allocated.
+func allocateUpdateNumberOfDirectory(op, updateDirectory string, opts AllocateOptions, result *Result) (*numberAllocation, error) {
+	updateDescriptorPath := filepath.Join(updateDirectory, constant.UPDATE_DESCRIPTOR_FILE)
+	originalData, err := ioutil.ReadFile(updateDescriptorPath)
+	if err != nil {
+		return nil, newError(op, err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	updateDescriptor, err := util.UnmarshalAnyUpdateDescriptor(originalData)
+	if err != nil {
+		return nil, newErrorWithCode(op, CodeDescriptorInvalid, err, fmt.Sprintf("'%s' format is incorrect.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	if util.ValidateUpdateNumber(updateDescriptor.Update_number) == nil {
+		result.addMessage(fmt.Sprintf("'%s' already has the update number %s. Allocation skipped.",
+			constant.UPDATE_DESCRIPTOR_FILE, updateDescriptor.Update_number))
+		return nil, nil
+	}
+	registryPath, err := getConfiguredRegistryPath(op, opts.RegistryPath, opts.KnownUpdatesDirectory)
+	if err != nil {
+		return nil, err
+	}
+	opts.PlatformVersion = updateDescriptor.Platform_version
+	opts.RegistryPath = registryPath
+	updateNumber, err := AllocateUpdateNumber(opts)
+	if err != nil {
+		return nil, newError(op, err, "")
+	}
+	allocation := &numberAllocation{
+		updateDescriptorPath: updateDescriptorPath,
+		originalData: originalData,
+		registryPath: registryPath,
+		platformVersion: updateDescriptor.Platform_version,
+		updateNumber: updateNumber,
+	}
+	data, err := util.SetUpdateNumber(originalData, updateNumber)
+	if err != nil {
+		allocation.undo(result)
+		return nil, newError(op, err, fmt.Sprintf("Error occurred while setting the update number in '%s'.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	err = ioutil.WriteFile(updateDescriptorPath, data, 0600)
+	if err != nil {
+		allocation.undo(result)
+		return nil, newError(op, err, fmt.Sprintf("Error occurred while saving the '%v'.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	result.addMessage(fmt.Sprintf("Update number %s reserved for platform version %s.", updateNumber,
+		updateDescriptor.Platform_version))
+	return allocation, nil
+}
+
+// This function will restore the update-descriptor.yaml and release the reserved update number. If either of them
+// fails, a warning is added to the result so that the author knows what is left.
+func (allocation *numberAllocation) undo(result *Result) {
+	err := ioutil.WriteFile(allocation.updateDescriptorPath, allocation.originalData, 0600)
+	if err != nil {
+		result.addWarning(fmt.Sprintf("Error occurred while restoring '%s'. It still has the update number %s. %v",
+			allocation.updateDescriptorPath, allocation.updateNumber, err))
+	}
+	err = releaseUpdateNumber(allocation.registryPath, allocation.platformVersion, allocation.updateNumber)
+	if err != nil {
+		result.addWarning(fmt.Sprintf("Update number %s of platform version %s is still reserved in '%s'. %v",
+			allocation.updateNumber, allocation.platformVersion, allocation.registryPath, err))
+		return
+	}
+	result.addMessage(fmt.Sprintf("Reservation of update number %s for platform version %s released.",
+		allocation.updateNumber, allocation.platformVersion))
+}
+
+// This function will remove the reservation of the given update number from the registry. Released updates are not
+// removed.
+func releaseUpdateNumber(registryPath, platformVersion, updateNumber string) error {
+	releaseLock, err := lockRegistry(opAllocate, registryPath)
+	if err != nil {
+		return err
+	}
+	defer releaseLock()
+
+	registry, err := loadRegistry(registryPath)
+	if err != nil {
+		return err
+	}
+	for i, entry := range registry.Updates {
+		if entry.PlatformVersion == platformVersion && entry.UpdateNumber == updateNumber &&
+			entry.Status == constant.REGISTRY_STATUS_RESERVED {
+			registry.Updates = append(registry.Updates[:i], registry.Updates[i + 1:]...)
+			return saveRegistry(registryPath, registry)
+		}
+	}
+	return nil
+}
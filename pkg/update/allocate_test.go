@@ -0,0 +1,115 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestAllocateUpdateNumber(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	// 0007 is the highest released update of 4.4.0. Updates of other platform versions should be ignored.
+	for _, filename := range []string{"WSO2-CARBON-UPDATE-4.4.0-0003.zip", "WSO2-CARBON-UPDATE-4.4.0-0007.zip",
+		"WSO2-CARBON-UPDATE-5.0.0-0100.zip", constant.README_FILE} {
+		if err := ioutil.WriteFile(filepath.Join(root, filename), []byte{}, 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+	updateNumbers := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			updateNumber, err := AllocateUpdateNumber(AllocateOptions{
+				PlatformVersion: "4.4.0",
+				KnownUpdatesDirectory: root,
+			})
+			if err != nil {
+				t.Errorf("Test failed. Unexpected error %v", err)
+				return
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			if updateNumbers[updateNumber] {
+				t.Errorf("Test failed. Update number %s allocated twice", updateNumber)
+			}
+			updateNumbers[updateNumber] = true
+		}()
+	}
+	waitGroup.Wait()
+	for _, updateNumber := range []string{"0008", "0009", "0010", "0011", "0012"} {
+		if !updateNumbers[updateNumber] {
+			t.Errorf("Test failed. Update number %s not allocated: %v", updateNumber, updateNumbers)
+		}
+	}
+
+	registry, err := loadRegistry(filepath.Join(root, constant.REGISTRY_FILE))
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(registry.Updates) != 5 || registry.Updates[0].Status != constant.REGISTRY_STATUS_RESERVED {
+		t.Errorf("Test failed. Unexpected registry: %v", registry.Updates)
+	}
+	if _, err := os.Stat(filepath.Join(root, constant.REGISTRY_FILE + constant.LOCK_FILE_EXTENSION)); !os.IsNotExist(err) {
+		t.Errorf("Test failed. Lock file was not removed: %v", err)
+	}
+
+	if _, err := AllocateUpdateNumber(AllocateOptions{PlatformVersion: "4.4.0"}); err == nil {
+		t.Error("Test failed. Expected an error when the registry is not configured")
+	}
+}
+
+func TestCreateReleasesReservation(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+	updateDirectory := filepath.Join(root, "update")
+	if err := os.Mkdir(updateDirectory, 0700); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	descriptor := "# Update number is allocated\nupdate_number: XXXX\nplatform_version: 4.4.0\n"
+	updateDescriptorPath := filepath.Join(updateDirectory, constant.UPDATE_DESCRIPTOR_FILE)
+	if err := ioutil.WriteFile(updateDescriptorPath, []byte(descriptor), 0600); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	// The distribution does not exist, so the update cannot be created after allocating the number
+	result, err := Create(CreateOptions{
+		UpdateDirectory: updateDirectory,
+		DistributionPath: filepath.Join(root, "wso2esb-4.9.0.zip"),
+		KnownUpdatesDirectory: root,
+		NextNumber: true,
+	})
+	if err == nil {
+		t.Fatal("Test failed. Expected an error when the distribution does not exist")
+	}
+	if data, _ := ioutil.ReadFile(updateDescriptorPath); string(data) != descriptor {
+		t.Errorf("Test failed, expected: %s, actual: %s", descriptor, string(data))
+	}
+	registry, err := loadRegistry(filepath.Join(root, constant.REGISTRY_FILE))
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(registry.Updates) != 0 {
+		t.Errorf("Test failed. Reservation was not released: %v", registry.Updates)
+	}
+	if result == nil || len(result.Warnings) != 0 {
+		t.Errorf("Test failed. Unexpected result: %v", result)
+	}
+}
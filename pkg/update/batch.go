@@ -0,0 +1,212 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// struct which is used to pass the values required to validate all updates in a directory
+type BatchOptions struct {
+	// Directory which contains the update zips. Only the files which match the update filename format are validated.
+	UpdatesDirectory      string
+	// Location of the distribution zip
+	DistributionPath      string
+	// Maximum number of updates which are validated concurrently. Number of CPUs is used if this is less than 1.
+	Workers               int
+	VerificationKey       string
+	KnownUpdatesDirectory string
+	ResourceFiles         ResourceFiles
+	ProductCatalog        map[string]map[string]string
+	// Whether placeholders in the update-descriptor.yaml should be reported as warnings instead of errors
+	AllowPlaceholders     bool
+	// Location of a .wumucignore file. See ValidateOptions.
+	IgnoreFile            string
+}
+
+// struct which is used to report the result of validating all updates in a directory
+type BatchReport struct {
+	Distribution string            `json:"distribution"`
+	Total        int               `json:"total"`
+	Passed       int               `json:"passed"`
+	Failed       int               `json:"failed"`
+	// Updates which have the same platform version and update number
+	Duplicates   []DuplicateUpdate `json:"duplicates"`
+	// Reports of all updates sorted by the update location
+	Updates      []*Report         `json:"updates"`
+}
+
+// struct which is used to store the updates which have the same platform version and update number
+type DuplicateUpdate struct {
+	PlatformVersion string   `json:"platform_version"`
+	UpdateNumber    string   `json:"update_number"`
+	Updates         []string `json:"updates"`
+}
+
+// ValidateBatch will validate all update zips in the given directory against the given distribution. Distribution is
+// read only once and updates are validated concurrently. Failures of individual updates are recorded in the returned
+// report. An error is returned only if the directory or the distribution cannot be read.
+func ValidateBatch(opts BatchOptions) (*BatchReport, error) {
+	updates, err := findUpdates(opts.UpdatesDirectory)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("updates: %v", updates))
+
+	//Read the distribution once. This will be shared between all workers
+	if !strings.HasSuffix(opts.DistributionPath, ".zip") {
+		return nil, newErrorWithCode(opValidate, CodeDistributionUnreadable, nil, fmt.Sprintf("Distribution must be a zip file. Entered file '%s' does not have a zip extension.", opts.DistributionPath))
+	}
+	ctx := &runContext{
+		productName: getProductName(opts.DistributionPath),
+	}
+	distributionFileMap, err := readDistributionZip(ctx, opts.DistributionPath)
+	if err != nil {
+		return nil, newErrorWithCode(opValidate, CodeDistributionUnreadable, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.DistributionPath))
+	}
+
+	resourceFiles := opts.ResourceFiles.getProductResourceFiles(ctx.productName)
+	ignorePatterns, err := util.LoadIgnorePatterns(opts.IgnoreFile)
+	if err != nil {
+		return nil, newError(opValidate, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.IgnoreFile))
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	logger.Debug(fmt.Sprintf("workers: %d", workers))
+
+	// Validate the updates using a fixed number of workers. Each worker writes to a different index of the slices, so
+	// no locking is required.
+	results := make([]*Result, len(updates))
+	reports := make([]*Report, len(updates))
+	jobs := make(chan int)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range jobs {
+				result, err := Validate(ValidateOptions{
+					UpdateFilePath: updates[index],
+					DistributionPath: opts.DistributionPath,
+					VerificationKey: opts.VerificationKey,
+					KnownUpdatesDirectory: opts.KnownUpdatesDirectory,
+					ResourceFiles: resourceFiles,
+					ProductCatalog: opts.ProductCatalog,
+					AllowPlaceholders: opts.AllowPlaceholders,
+					ignorePatterns: ignorePatterns,
+					distributionFileMap: distributionFileMap,
+				})
+				results[index] = result
+				reports[index] = NewReport(updates[index], result, err)
+			}
+		}()
+	}
+	for index := range updates {
+		jobs <- index
+	}
+	close(jobs)
+	waitGroup.Wait()
+
+	batchReport := &BatchReport{
+		Distribution: filepath.ToSlash(opts.DistributionPath),
+		Total: len(updates),
+		Duplicates: findDuplicateUpdates(updates, results, reports),
+		Updates: reports,
+	}
+	for _, report := range reports {
+		if report.Valid {
+			batchReport.Passed++
+		} else {
+			batchReport.Failed++
+		}
+	}
+	return batchReport, nil
+}
+
+// This function will return the locations of all files in the given directory which match the update filename format.
+// Locations are sorted so that the reports are always in the same order.
+func findUpdates(updatesDirectory string) ([]string, error) {
+	exists, err := util.IsDirectoryExists(updatesDirectory)
+	if err != nil {
+		return nil, newError(opValidate, err, fmt.Sprintf("Error occurred while checking '%s'", updatesDirectory))
+	}
+	if !exists {
+		return nil, newError(opValidate, nil, fmt.Sprintf("Directory does not exist at '%s'.", updatesDirectory))
+	}
+	files, err := ioutil.ReadDir(updatesDirectory)
+	if err != nil {
+		return nil, newError(opValidate, err, fmt.Sprintf("Error occurred while reading '%s'.", updatesDirectory))
+	}
+	regex, err := regexp.Compile(constant.FILENAME_REGEX)
+	if err != nil {
+		return nil, newError(opValidate, err, "")
+	}
+	updates := make([]string, 0)
+	for _, file := range files {
+		if !file.IsDir() && regex.MatchString(file.Name()) {
+			updates = append(updates, filepath.Join(updatesDirectory, file.Name()))
+		}
+	}
+	sort.Strings(updates)
+	return updates, nil
+}
+
+// This function will find the updates which have the same platform version and update number. Reports of duplicate
+// updates which are otherwise valid are marked as failed.
+func findDuplicateUpdates(updates []string, results []*Result, reports []*Report) []DuplicateUpdate {
+	indicesMap := make(map[string][]int)
+	keys := make([]string, 0)
+	for index, result := range results {
+		if result == nil || len(result.UpdateNumber) == 0 {
+			continue
+		}
+		key := result.PlatformVersion + "-" + result.UpdateNumber
+		if _, found := indicesMap[key]; !found {
+			keys = append(keys, key)
+		}
+		indicesMap[key] = append(indicesMap[key], index)
+	}
+	sort.Strings(keys)
+
+	duplicates := make([]DuplicateUpdate, 0)
+	for _, key := range keys {
+		indices := indicesMap[key]
+		if len(indices) < 2 {
+			continue
+		}
+		duplicate := DuplicateUpdate{
+			PlatformVersion: results[indices[0]].PlatformVersion,
+			UpdateNumber: results[indices[0]].UpdateNumber,
+		}
+		for _, index := range indices {
+			duplicate.Updates = append(duplicate.Updates, filepath.ToSlash(updates[index]))
+		}
+		for _, index := range indices {
+			if !reports[index].Valid {
+				continue
+			}
+			reports[index].Valid = false
+			reports[index].Error = &Error{
+				Op: opValidate,
+				Code: CodeDuplicateUpdateNumber,
+				Message: fmt.Sprintf("Update number '%s' of platform version '%s' is used by %s.",
+					duplicate.UpdateNumber, duplicate.PlatformVersion, strings.Join(duplicate.Updates, ", ")),
+			}
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	return duplicates
+}
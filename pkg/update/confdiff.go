@@ -0,0 +1,115 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+	"gopkg.in/yaml.v2"
+)
+
+// This function will build the structured diffs of the given modified files which are configuration files and save
+// them in the conf-diffs.yaml in the staging directory. Files are compared with the same files in the distribution.
+// Modified files are still copied to carbon.home so the diffs are only an additional artifact. If a file cannot be
+// parsed, a warning is added and the file is skipped.
+func saveConfDiffs(ctx *runContext, distributionPath string, modifiedFiles []string, result *Result) error {
+	distributionFilePaths := make([]string, 0)
+	for _, modifiedFile := range modifiedFiles {
+		if len(util.GetConfFormat(modifiedFile)) > 0 {
+			distributionFilePaths = append(distributionFilePaths, path.Join(ctx.productName, modifiedFile))
+		}
+	}
+	logger.Debug(fmt.Sprintf("Configuration files: %v", distributionFilePaths))
+	if len(distributionFilePaths) == 0 {
+		return nil
+	}
+	distributionFilesMap, err := util.ReadFilesFromZip(distributionPath, distributionFilePaths)
+	if err != nil {
+		return newErrorWithCode(opCreate, CodeDistributionUnreadable, err, fmt.Sprintf("Error occurred while reading '%s'.", distributionPath))
+	}
+
+	confDiffs := util.ConfDiffs{
+		Files: make([]util.ConfDiff, 0),
+	}
+	for _, modifiedFile := range modifiedFiles {
+		format := util.GetConfFormat(modifiedFile)
+		if len(format) == 0 {
+			continue
+		}
+		original := distributionFilesMap[path.Join(ctx.productName, modifiedFile)]
+		updated, err := ioutil.ReadFile(path.Join(ctx.stagingDirectory, ctx.updateName, constant.CARBON_HOME, modifiedFile))
+		if err != nil {
+			return newError(opCreate, err, fmt.Sprintf("Error occurred while reading '%s'.", modifiedFile))
+		}
+		changes, err := util.DiffConf(format, original, updated)
+		if err != nil {
+			result.addWarning(fmt.Sprintf("Structured diff of '%s' was not created. %v", modifiedFile, err))
+			continue
+		}
+		logger.Debug(fmt.Sprintf("Changes of %s: %v", modifiedFile, changes))
+		if len(changes) == 0 {
+			continue
+		}
+		confDiffs.Files = append(confDiffs.Files, util.ConfDiff{
+			File: modifiedFile,
+			Format: format,
+			Changes: changes,
+		})
+	}
+	if len(confDiffs.Files) == 0 {
+		return nil
+	}
+
+	data, err := yaml.Marshal(confDiffs)
+	if err != nil {
+		return newError(opCreate, err, fmt.Sprintf("Error occurred while marshalling the '%s'.", constant.CONF_DIFFS_FILE))
+	}
+	err = ioutil.WriteFile(path.Join(ctx.stagingDirectory, ctx.updateName, constant.CONF_DIFFS_FILE), data, 0600)
+	if err != nil {
+		return newError(opCreate, err, fmt.Sprintf("Error occurred while saving the '%s'.", constant.CONF_DIFFS_FILE))
+	}
+	result.addMessage(fmt.Sprintf("Structured diffs of %d configuration file(s) saved to '%s'.", len(confDiffs.Files),
+		constant.CONF_DIFFS_FILE))
+	return nil
+}
+
+// This function will check whether the diffs in the conf-diffs.yaml of the update apply cleanly to the configuration
+// files in the distribution.
+func checkConfDiffs(ctx *runContext, distributionPath string, confDiffs *util.ConfDiffs) error {
+	distributionFilePaths := make([]string, 0)
+	for _, confDiff := range confDiffs.Files {
+		distributionFilePaths = append(distributionFilePaths, path.Join(ctx.productName, confDiff.File))
+	}
+	distributionFilesMap, err := util.ReadFilesFromZip(distributionPath, distributionFilePaths)
+	if err != nil {
+		return newErrorWithCode(opValidate, CodeDistributionUnreadable, err, fmt.Sprintf("Error occurred while reading '%s'.", distributionPath))
+	}
+
+	conflicts := make([]string, 0)
+	for _, confDiff := range confDiffs.Files {
+		data, found := distributionFilesMap[path.Join(ctx.productName, confDiff.File)]
+		if !found {
+			conflicts = append(conflicts, fmt.Sprintf("'%s' not found in the distribution.", confDiff.File))
+			continue
+		}
+		fileConflicts, err := util.CheckConfChanges(confDiff.Format, data, confDiff.Changes)
+		if err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("'%s' in the distribution cannot be read. %v", confDiff.File, err))
+			continue
+		}
+		for _, conflict := range fileConflicts {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", confDiff.File, conflict))
+		}
+	}
+	logger.Debug(fmt.Sprintf("conflicts: %v", conflicts))
+	if len(conflicts) > 0 {
+		return newErrorWithCode(opValidate, CodeConfDiffConflict, nil, fmt.Sprintf("Diffs in the '%s' file do not apply to the distribution.\n%s",
+			constant.CONF_DIFFS_FILE, strings.Join(conflicts, "\n")))
+	}
+	return nil
+}
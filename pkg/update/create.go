@@ -0,0 +1,1082 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Operations which are used in the errors returned by this package.
+const (
+	opCreate = "create"
+	opValidate = "validate"
+	opInit = "init"
+	opDescriptor = "descriptor"
+	opAllocate = "allocate"
+	opRegistry = "registry"
+	opNotes = "notes"
+)
+
+// struct which is used to pass the values required to create an update
+type CreateOptions struct {
+	// Directory which contains the updated files and the resource files
+	UpdateDirectory       string
+	// Location of the distribution zip
+	DistributionPath      string
+	// Directory or zip file where the update zip should be created. If this is empty, the update zip is created in the
+	// current working directory.
+	OutputLocation        string
+	// Directory used to stage the files before creating the update zip. If this is empty, a new directory is created
+	// in the OS temp location and it is deleted before returning. If a directory is given, the caller should delete it.
+	StagingDirectory      string
+	// Private key used to sign the update zip. Update zip is not signed if this is empty.
+	SigningKey            string
+	// Public key used to verify the signature when validating the created update
+	VerificationKey       string
+	// Directory of known updates used to check the 'requires' and 'supersedes' fields
+	KnownUpdatesDirectory string
+	// Whether files with the same MD5 sum as the file in the distribution should be copied to the update
+	CheckMd5Disabled      bool
+	ResourceFiles         ResourceFiles
+	ProductCatalog        map[string]map[string]string
+	// Whether placeholders in the update-descriptor.yaml should be reported as warnings instead of errors
+	AllowPlaceholders     bool
+	// Whether the next update number should be allocated if the update-descriptor.yaml does not have a valid update
+	// number. The number is allocated using the KnownUpdatesDirectory and the RegistryPath.
+	NextNumber            bool
+	// Location of the registry of update numbers. See AllocateOptions.
+	RegistryPath          string
+	// Prompter used when the location of a file in the distribution cannot be identified. If this is nil, an error is
+	// returned in such situations.
+	Prompter              Prompter
+}
+
+// Prompter is used to get the decisions of the user when the location of a file in the distribution cannot be
+// identified automatically.
+type Prompter interface {
+	// AddAsNewFile is called when a file or a directory in the update is not found in the distribution. It should
+	// return whether it should be added as a new file.
+	AddAsNewFile(filename string) (bool, error)
+	// GetNewFileLocation should return the destination directory of a new file relative to CARBON_HOME.
+	GetNewFileLocation(filename string) (string, error)
+	// ConfirmNewLocation is called when the destination directory of a new file is not found in the distribution. It
+	// should return constant.YES to copy anyway, constant.NO to skip copying or constant.REENTER to enter the
+	// location again.
+	ConfirmNewLocation(filename, location string) (int, error)
+	// SelectLocations is called when a file or a directory is found in multiple locations in the distribution. It
+	// should return the selected locations. Copying is skipped if no location is selected.
+	SelectLocations(filename string, locations []string) ([]string, error)
+}
+
+// This struct is used to store file/directory information.
+type data struct {
+	name         string
+	isDir        bool
+	relativePath string
+	md5          string
+}
+
+// This struct used to store directory structure of the distribution.
+type node struct {
+	name             string
+	isDir            bool
+	relativeLocation string
+	parent           *node
+	childNodes       map[string]*node
+	md5Hash          string
+}
+
+// This is used to create a new node which will initialize the childNodes map.
+func createNewNode() node {
+	return node{
+		childNodes: make(map[string]*node),
+	}
+}
+
+// Create will create a new update zip using the files in the update directory. The update is validated against the
+// distribution after creating it. If NextNumber is set and an update number is allocated, the update-descriptor.yaml is
+// restored and the reservation is released if the update zip is not created.
+func Create(opts CreateOptions) (*Result, error) {
+	result := &Result{}
+	if !opts.NextNumber {
+		return create(opts, result)
+	}
+
+	// Allocate the next update number before reading the update-descriptor.yaml so that it is validated as usual
+	allocation, err := allocateUpdateNumberOfDirectory(opCreate, opts.UpdateDirectory, AllocateOptions{
+		KnownUpdatesDirectory: opts.KnownUpdatesDirectory,
+		RegistryPath: opts.RegistryPath,
+	}, result)
+	if err != nil {
+		return result, err
+	}
+	createResult, err := create(opts, result)
+	if err == nil || allocation == nil {
+		return createResult, err
+	}
+	// The update zip has the allocated number once it is created, so the reservation is kept after that
+	if len(result.OutputPath) > 0 {
+		result.addWarning(fmt.Sprintf("Update number %s of platform version %s is kept reserved because '%s' was "+
+			"created.", allocation.updateNumber, allocation.platformVersion, result.OutputPath))
+	} else {
+		allocation.undo(result)
+	}
+	return result, err
+}
+
+// This function will create the update zip. Messages and warnings are added to the given result.
+func create(opts CreateOptions, result *Result) (*Result, error) {
+	opts.ResourceFiles = opts.ResourceFiles.getProductResourceFiles(getProductName(opts.DistributionPath))
+
+	//1) - 5) Check the update directory and the distribution and read the update-descriptor.yaml
+	updateDescriptor, _, err := readUpdateDirectory(opCreate, opts.UpdateDirectory, opts.DistributionPath, opts.ProductCatalog, opts.AllowPlaceholders, result)
+	if err != nil {
+		return nil, err
+	}
+	updateRoot := strings.TrimSuffix(opts.UpdateDirectory, constant.PATH_SEPARATOR)
+	logger.Debug(fmt.Sprintf("updateRoot: %s\n", updateRoot))
+	ctx := &runContext{
+		updateRoot: updateRoot,
+	}
+
+	// set the update name
+	updateName := getUpdateName(updateDescriptor, constant.UPDATE_NAME_PREFIX)
+	ctx.updateName = updateName
+	result.UpdateName = updateName
+
+	// Get ignored files. These files wont be stored in the data structure. So matches will not be searched for these
+	// files
+	ignoredFiles := opts.ResourceFiles.getIgnoredFiles()
+	logger.Debug(fmt.Sprintf("Ignored files: %v", ignoredFiles))
+
+	// Get the ignore patterns in the .wumucignore of the update directory
+	ignorePatterns, err := util.LoadIgnorePatterns(filepath.Join(opts.UpdateDirectory, constant.IGNORE_FILE))
+	if err != nil {
+		return nil, newError(opCreate, err, fmt.Sprintf("Error occurred while reading '%s'.", constant.IGNORE_FILE))
+	}
+
+	//6) Traverse and read the update
+
+	// allFilesMap - Map which contains details of all files in the directory. Key will be relativePath of the file.
+	// rootLevelDirectoriesMap - Map which have all directories in the root of the given directory. Key will be the directory path.
+	// rootLevelFilesMap - Map which have all files in the root of the given directory. Key will be the file path.
+	allFilesMap, rootLevelDirectoriesMap, rootLevelFilesMap, err := readDirectory(opts.UpdateDirectory, ignoredFiles, ignorePatterns, result)
+	if err != nil {
+		return nil, newError(opCreate, err, "Error occurred while reading update directory.")
+	}
+
+	logger.Debug(fmt.Sprintf("allFilesMap: %v\n", allFilesMap))
+	logger.Debug(fmt.Sprintf("rootLevelDirectoriesMap: %v\n", rootLevelDirectoriesMap))
+	logger.Debug(fmt.Sprintf("rootLevelFilesMap: %v\n", rootLevelFilesMap))
+
+	// Get the product name from the distribution path and store it in the context
+	paths := strings.Split(opts.DistributionPath, constant.PATH_SEPARATOR)
+	distributionName := strings.TrimSuffix(paths[len(paths) - 1], ".zip")
+	ctx.productName = distributionName
+
+	// Read the distribution zip file. rootNode is what we use as the root of the distribution when we populate tree
+	// like structure.
+	logger.Debug("Reading zip")
+	rootNode, err := readZip(ctx, opts.DistributionPath)
+	if err != nil {
+		return nil, newErrorWithCode(opCreate, CodeDistributionUnreadable, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.DistributionPath))
+	}
+	logger.Debug("Reading zip finished")
+
+	logger.Trace("Top level nodes ---------------------")
+	for name, node := range rootNode.childNodes {
+		logger.Trace(fmt.Sprintf("%s: %v", name, node))
+	}
+	logger.Trace("-------------------------------------")
+
+	// Create a new staging directory for this run if a directory is not given so that parallel runs will not use the
+	// same directory
+	stagingDirectory := opts.StagingDirectory
+	if len(stagingDirectory) == 0 {
+		stagingDirectory, err = ioutil.TempDir("", constant.TEMP_DIR_PREFIX)
+		if err != nil {
+			return nil, newError(opCreate, err, "Error occurred while creating the staging directory.")
+		}
+		defer util.CleanUpDirectory(stagingDirectory)
+	}
+	logger.Debug(fmt.Sprintf("stagingDirectory: %s", stagingDirectory))
+	ctx.stagingDirectory = stagingDirectory
+
+	//todo: save the selected location to generate the final summary map
+	//7) Find matches
+
+	// Find matches in the distribution for all directories in the root level of the update directory
+	logger.Debug("Checking Directories:")
+	for directoryName := range rootLevelDirectoriesMap {
+		err = processMatches(ctx, directoryName, true, allFilesMap, &rootNode, updateDescriptor, &opts, result)
+		if err != nil {
+			return nil, newError(opCreate, err, "")
+		}
+	}
+
+	// Find matches in the distribution for all files in the root level of the update directory
+	logger.Debug("Checking Files:")
+	for fileName := range rootLevelFilesMap {
+		err = processMatches(ctx, fileName, false, allFilesMap, &rootNode, updateDescriptor, &opts, result)
+		if err != nil {
+			return nil, newError(opCreate, err, "")
+		}
+	}
+
+	//8) Copy resource files (update-descriptor.yaml, etc) to the staging directory
+	err = copyResourceFilesToTempDir(ctx, opts.ResourceFiles, ignorePatterns, result)
+	if err != nil {
+		return nil, newError(opCreate, err, "Error occurred while copying resource files.")
+	}
+
+	// Sort the file changes so that the update-descriptor does not depend on the order the files were processed
+	sort.Strings(updateDescriptor.File_changes.Added_files)
+	sort.Strings(updateDescriptor.File_changes.Removed_files)
+	sort.Strings(updateDescriptor.File_changes.Modified_files)
+	result.setFileChanges(updateDescriptor)
+
+	// Save the structured diffs of the modified configuration files so that they can be applied without replacing the
+	// customizations in the files
+	err = saveConfDiffs(ctx, opts.DistributionPath, updateDescriptor.File_changes.Modified_files, result)
+	if err != nil {
+		return nil, err
+	}
+
+	// Save the update-descriptor with the updated, newly added files to the staging directory. Only the file_changes
+	// section is changed so that the comments and the formatting of the author are kept.
+	data, err := ioutil.ReadFile(filepath.Join(updateRoot, constant.UPDATE_DESCRIPTOR_FILE))
+	if err != nil {
+		return nil, newError(opCreate, err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	data, err = util.SetFileChanges(data, util.FileChangesV2{
+		AddedFiles: getNonNilList(updateDescriptor.File_changes.Added_files),
+		RemovedFiles: getNonNilList(updateDescriptor.File_changes.Removed_files),
+		ModifiedFiles: getNonNilList(updateDescriptor.File_changes.Modified_files),
+	})
+	if err != nil {
+		return nil, newError(opCreate, err, "Error occurred while marshalling the update-descriptor.")
+	}
+	err = saveUpdateDescriptor(ctx, constant.UPDATE_DESCRIPTOR_FILE, data)
+	if err != nil {
+		return nil, newError(opCreate, err, fmt.Sprintf("Error occurred while saving the '%v'.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+
+	// Construct the update zip name
+	updateZipName, err := getUpdateZipPath(opts.OutputLocation, updateName)
+	if err != nil {
+		return nil, newError(opCreate, err, "Error occurred while creating the output directory.")
+	}
+	logger.Debug(fmt.Sprintf("updateZipName: %s", updateZipName))
+
+	targetDirectory := path.Join(stagingDirectory, updateName)
+	targetDirectory = strings.Replace(targetDirectory, "/", constant.PATH_SEPARATOR, -1)
+
+	logger.Debug(fmt.Sprintf("targetDirectory: %s", targetDirectory))
+	timestamp, err := util.GetZipTimestamp()
+	if err != nil {
+		return nil, newError(opCreate, err, "")
+	}
+	err = util.ZipDirectory(updateZipName, targetDirectory, timestamp)
+	if err != nil {
+		return nil, newError(opCreate, err, "")
+	}
+	result.OutputPath = updateZipName
+
+	// Sign the update zip if a private key is given
+	if len(opts.SigningKey) > 0 {
+		signaturePath, err := util.SignFile(updateZipName, opts.SigningKey)
+		if err != nil {
+			return nil, newError(opCreate, err, fmt.Sprintf("Error occurred while signing '%s'.", updateZipName))
+		}
+		result.SignaturePath = signaturePath
+	}
+
+	// Validate the created update
+	validateOptions := ValidateOptions{
+		UpdateFilePath: updateZipName,
+		DistributionPath: opts.DistributionPath,
+		VerificationKey: opts.VerificationKey,
+		KnownUpdatesDirectory: opts.KnownUpdatesDirectory,
+		ResourceFiles: opts.ResourceFiles,
+		ProductCatalog: opts.ProductCatalog,
+		AllowPlaceholders: opts.AllowPlaceholders,
+		ignorePatterns: ignorePatterns,
+	}
+	err = validateUpdate(&validateOptions, updateName, result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// This function will check whether the given update directory and the distribution exist and read the
+// update-descriptor.yaml in the update directory. Both version 1 and version 2 descriptors are returned using the version
+// 1 struct. If the descriptor is a version 2 descriptor, the original is returned as well so that it can be saved in the
+// same format. Placeholders are checked before validating the descriptor so that their exact locations are reported.
+func readUpdateDirectory(op, updateDirectory, distributionPath string, productCatalog map[string]map[string]string, allowPlaceholders bool, result *Result) (*util.UpdateDescriptor, *util.UpdateDescriptorV2, error) {
+	//1) Check whether the given update directory exists
+	exists, err := util.IsDirectoryExists(updateDirectory)
+	if err != nil {
+		return nil, nil, newError(op, err, "Error occurred while reading the update directory")
+	}
+	logger.Debug(fmt.Sprintf("exists: %v", exists))
+	if !exists {
+		return nil, nil, newError(op, nil, fmt.Sprintf("Directory does not exist at '%s'. Update location must be a directory.", updateDirectory))
+	}
+
+	//2) Check whether the update-descriptor.yaml file exists
+	// Construct the update-descriptor.yaml file location
+	updateDescriptorPath := path.Join(updateDirectory, constant.UPDATE_DESCRIPTOR_FILE)
+	exists, err = util.IsFileExists(updateDescriptorPath)
+	if err != nil {
+		return nil, nil, newError(op, err, fmt.Sprintf("Error occurred while reading the '%v'", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	if !exists {
+		return nil, nil, newError(op, nil, fmt.Sprintf("'%s' not found at '%s' directory.", constant.UPDATE_DESCRIPTOR_FILE, updateDirectory))
+	}
+	logger.Debug(fmt.Sprintf("Descriptor Exists. Location %s", updateDescriptorPath))
+
+	//3) Check whether the given distribution exists
+	exists, err = util.IsFileExists(distributionPath)
+	if err != nil {
+		return nil, nil, newError(op, err, fmt.Sprintf("Error occurred while checking '%s'", distributionPath))
+	}
+	if !exists {
+		return nil, nil, newErrorWithCode(op, CodeDistributionUnreadable, nil, fmt.Sprintf("File does not exist at '%s'. Distribution must be a zip file.", distributionPath))
+	}
+	if !strings.HasSuffix(distributionPath, ".zip") {
+		return nil, nil, newErrorWithCode(op, CodeDistributionUnreadable, nil, fmt.Sprintf("Entered update location '%s' does not have a 'zip' extention.", distributionPath))
+	}
+
+	//4) Read update-descriptor.yaml and check for placeholders
+	data, err := ioutil.ReadFile(updateDescriptorPath)
+	if err != nil {
+		return nil, nil, newError(op, err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	err = checkPlaceholders(op, data, allowPlaceholders, result)
+	if err != nil {
+		return nil, nil, err
+	}
+	descriptorVersion, err := util.GetDescriptorVersionOfFile(constant.UPDATE_DESCRIPTOR_FILE, updateDirectory)
+	if err != nil {
+		return nil, nil, newError(op, err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	logger.Debug(fmt.Sprintf("descriptorVersion: %s", descriptorVersion))
+
+	var updateDescriptor *util.UpdateDescriptor
+	var updateDescriptorV2 *util.UpdateDescriptorV2
+
+	if descriptorVersion == constant.DESCRIPTOR_VERSION_2 {
+		updateDescriptorV2, err = util.LoadUpdateDescriptorV2(constant.UPDATE_DESCRIPTOR_FILE, updateDirectory)
+		if err != nil {
+			return nil, nil, newError(op, err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+		}
+
+		//5) Validate the file format
+		err = util.ValidateUpdateDescriptorV2(updateDescriptorV2)
+		if err != nil {
+			return nil, nil, newErrorWithCode(op, CodeDescriptorInvalid, err, fmt.Sprintf("'%s' format is incorrect.", constant.UPDATE_DESCRIPTOR_FILE))
+		}
+		updateDescriptor = util.ConvertToUpdateDescriptorV1(updateDescriptorV2)
+	} else {
+		updateDescriptor, err = util.LoadUpdateDescriptor(constant.UPDATE_DESCRIPTOR_FILE, updateDirectory)
+		if err != nil {
+			return nil, nil, newError(op, err, fmt.Sprintf("Error occurred when reading '%s' file.", constant.UPDATE_DESCRIPTOR_FILE))
+		}
+
+		//5) Validate the file format
+		err = util.ValidateUpdateDescriptor(updateDescriptor)
+		if err != nil {
+			return nil, nil, newErrorWithCode(op, CodeDescriptorInvalid, err, fmt.Sprintf("'%s' format is incorrect.", constant.UPDATE_DESCRIPTOR_FILE))
+		}
+	}
+	err = validateProducts(updateDescriptor, productCatalog)
+	if err != nil {
+		return nil, nil, newErrorWithCode(op, CodeDescriptorInvalid, err, fmt.Sprintf("'%s' format is incorrect.", constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	return updateDescriptor, updateDescriptorV2, nil
+}
+
+// This function will return the location of the update zip. If the output location has the zip extension, it will be
+// used as the update zip. Otherwise it is considered as a directory and the update zip will be created inside it.
+// Parent directories are created if they do not exist.
+func getUpdateZipPath(outputLocation, updateName string) (string, error) {
+	updateZipName := updateName + ".zip"
+	if len(outputLocation) == 0 {
+		return updateZipName, nil
+	}
+	outputDirectory := outputLocation
+	if strings.HasSuffix(outputLocation, ".zip") {
+		outputDirectory = filepath.Dir(outputLocation)
+	}
+	err := util.CreateDirectory(outputDirectory)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasSuffix(outputLocation, ".zip") {
+		return outputLocation, nil
+	}
+	return filepath.Join(outputLocation, updateZipName), nil
+}
+
+// This function will set the update name which will be used when creating the update zip.
+func getUpdateName(updateDescriptor *util.UpdateDescriptor, updateNamePrefix string) string {
+	// Read the corresponding details from the struct
+	platformVersion := updateDescriptor.Platform_version
+	updateNumber := updateDescriptor.Update_number
+	updateName := updateNamePrefix + "-" + platformVersion + "-" + updateNumber
+	return updateName
+}
+
+// This function will find the matches of the given file or directory in the distribution and act according to the
+// number of matches found.
+func processMatches(ctx *runContext, filename string, isDir bool, allFilesMap map[string]data, rootNode *node, updateDescriptor *util.UpdateDescriptor, opts *CreateOptions, result *Result) error {
+	// This will be used to store all the matches (matching locations in for the given file or directory)
+	matches := make(map[string]*node)
+	logger.Debug(fmt.Sprintf("Name: %s", filename))
+	FindMatches(rootNode, filename, isDir, matches)
+	logger.Debug(fmt.Sprintf("matches: %v", matches))
+
+	// Now we can act according to the number of matches we found
+	switch len(matches){
+	// No match found in the distribution
+	case 0:
+		logger.Debug("\nNo match found\n")
+		return handleNoMatch(ctx, filename, isDir, allFilesMap, rootNode, updateDescriptor, opts, result)
+	// Single match found in the distribution
+	case 1:
+		logger.Debug("\nSingle match found\n")
+		// Get the matching node from the map. For this, we need to iterate through the map. Map size will always
+		// be 1 because we check the size above.
+		var match *node
+		for _, node := range matches {
+			match = node
+		}
+		return handleSingleMatch(ctx, filename, match, isDir, allFilesMap, rootNode, updateDescriptor, opts, result)
+	// Multiple matches found in the distribution
+	default:
+		logger.Debug("\nMultiple matches found\n")
+		return handleMultipleMatches(ctx, filename, isDir, matches, allFilesMap, rootNode, updateDescriptor, opts, result)
+	}
+}
+
+// This function will handle no match found for a file situations. Prompter is used to decide how to proceed.
+func handleNoMatch(ctx *runContext, filename string, isDir bool, allFilesMap map[string]data, rootNode *node, updateDescriptor *util.UpdateDescriptor, opts *CreateOptions, result *Result) error {
+	//todo: Check OSGi bundles in the plugins directory
+	logger.Debug(fmt.Sprintf("[NO MATCH] %s", filename))
+	if opts.Prompter == nil {
+		return errors.New(fmt.Sprintf("'%s' not found in distribution.", filename))
+	}
+	addAsNewFile, err := opts.Prompter.AddAsNewFile(filename)
+	if err != nil {
+		return newError(opCreate, err, "Error occurred while getting input from the user.")
+	}
+	if !addAsNewFile {
+		result.addWarning(fmt.Sprintf("Skipping copying: %s", filename))
+		return nil
+	}
+	// Handle the file/directory as new
+	return handleNewFile(ctx, filename, isDir, rootNode, allFilesMap, updateDescriptor, opts, result)
+}
+
+// This function will handle the situations where the user want to add a file as a new file which was not found in the distribution.
+func handleNewFile(ctx *runContext, filename string, isDir bool, rootNode *node, allFilesMap map[string]data, updateDescriptor *util.UpdateDescriptor, opts *CreateOptions, result *Result) error {
+	logger.Debug(fmt.Sprintf("[HANDLE NEW] %s", filename))
+
+	updateRoot := ctx.updateRoot
+	if len(updateRoot) == 0 {
+		return errors.New("updateRoot path length is 0.")
+	}
+
+	readDestinationLoop:
+	for {
+		// Get user preference
+		relativeLocationInDistribution, err := opts.Prompter.GetNewFileLocation(filename)
+		if err != nil {
+			return newError(opCreate, err, "Error occurred while getting input from the user.")
+		}
+		// Trim the path separators at the beginning and the end of the path if present.
+		relativeLocationInDistribution = strings.TrimPrefix(relativeLocationInDistribution, constant.PATH_SEPARATOR)
+		relativeLocationInDistribution = strings.TrimSuffix(relativeLocationInDistribution, constant.PATH_SEPARATOR)
+		logger.Debug("relativePath:", relativeLocationInDistribution)
+
+		// Check whether the directory which user entered is already in the distribution.
+		var exists bool
+		if isDir {
+			// If currently processing a directory, construct the full path and check.
+			//todo: is this necessary?
+			fullPath := path.Join(relativeLocationInDistribution, filename)
+			logger.Debug(fmt.Sprintf("Checking: %s", fullPath))
+			exists = PathExists(rootNode, fullPath, true)
+			logger.Debug(fmt.Sprintf("%s exists: %v", fullPath, exists))
+		} else {
+			// If currently processing a file, no need to construct the full path. We can directly check the
+			// entered directory.
+			logger.Debug("Checking:", relativeLocationInDistribution)
+			exists = PathExists(rootNode, relativeLocationInDistribution, true)
+			logger.Debug(relativeLocationInDistribution + " exists:", exists)
+		}
+
+		// If the directory is already in the distribution or the user entered the distribution root, copy the files.
+		if exists || len(relativeLocationInDistribution) == 0 {
+			return copyNewFile(ctx, filename, isDir, updateRoot, relativeLocationInDistribution, allFilesMap, rootNode, updateDescriptor)
+		}
+
+		// If the directory is not found and the relative location is not the distribution root, confirm the location
+		for {
+			preference, err := opts.Prompter.ConfirmNewLocation(filename, relativeLocationInDistribution)
+			if err != nil {
+				return newError(opCreate, err, "Error occurred while getting input from the user.")
+			}
+			switch(preference){
+			case constant.YES:
+				return copyNewFile(ctx, filename, isDir, updateRoot, relativeLocationInDistribution, allFilesMap, rootNode, updateDescriptor)
+			case constant.NO:
+				result.addWarning(fmt.Sprintf("Skipping copying %s", filename))
+				return nil
+			case constant.REENTER:
+				continue readDestinationLoop
+			}
+		}
+	}
+}
+
+// This function will copy the given new file or all files which are in the given new directory and subdirectories to
+// the given location.
+func copyNewFile(ctx *runContext, filename string, isDir bool, updateRoot, relativeLocationInDistribution string, allFilesMap map[string]data, rootNode *node, updateDescriptor *util.UpdateDescriptor) error {
+	if !isDir {
+		// If we are processing a file, copy the file to the staging directory
+		logger.Debug(fmt.Sprintf("[Copy] %s ; From: %s ; To: %s", filename, updateRoot, relativeLocationInDistribution))
+		return copyFile(ctx, filename, updateRoot, relativeLocationInDistribution, rootNode, updateDescriptor)
+	}
+	// Get all matching files. By matching files, we mean all the files which are in the directory and subdirectories.
+	allMatchingFiles := getAllMatchingFiles(filename, allFilesMap)
+	logger.Debug(fmt.Sprintf("Copying all matches:\n%s", allMatchingFiles))
+	// Copy all matching files to the staging directory
+	for _, match := range allMatchingFiles {
+		logger.Debug(fmt.Sprintf("[Copy] %s ; From: %s ; To: %s", match, updateRoot, relativeLocationInDistribution))
+		err := copyFile(ctx, match, updateRoot, relativeLocationInDistribution, rootNode, updateDescriptor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// This function will situations where a single match is found in the distribution.
+func handleSingleMatch(ctx *runContext, filename string, matchingNode *node, isDir bool, allFilesMap map[string]data, rootNode *node, updateDescriptor *util.UpdateDescriptor, opts *CreateOptions, result *Result) error {
+	logger.Debug(fmt.Sprintf("[SINGLE MATCH] %s ; match: %s", filename, matchingNode.relativeLocation))
+	return copyToLocation(ctx, filename, matchingNode.relativeLocation, isDir, allFilesMap, rootNode, updateDescriptor, opts, result)
+}
+
+// This function will handle multiple match situations. In here user input is required.
+func handleMultipleMatches(ctx *runContext, filename string, isDir bool, matches map[string]*node, allFilesMap map[string]data, rootNode *node, updateDescriptor *util.UpdateDescriptor, opts *CreateOptions, result *Result) error {
+	logger.Debug(fmt.Sprintf("[MULTIPLE MATCHES] %s", filename))
+
+	// Sort the locations so that they are always shown in the same order
+	locations := make([]string, 0)
+	for location := range matches {
+		locations = append(locations, location)
+	}
+	sort.Strings(locations)
+
+	if opts.Prompter == nil {
+		return errors.New(fmt.Sprintf("Multiple matches found for '%s' in the distribution: %s", filename,
+			strings.Join(locations, ", ")))
+	}
+	selectedLocations, err := opts.Prompter.SelectLocations(filename, locations)
+	if err != nil {
+		return newError(opCreate, err, "Error occurred while getting input from the user.")
+	}
+	// Check whether the user skipped copying
+	if len(selectedLocations) == 0 {
+		logger.Debug(fmt.Sprintf("Skipping copying '%s'", filename))
+		result.addWarning(fmt.Sprintf("0 entered. Skipping copying '%s'.", filename))
+		return nil
+	}
+	// Copy the file or directory to all selected locations
+	for _, pathInDistribution := range selectedLocations {
+		logger.Debug(fmt.Sprintf("[MULTIPLE MATCHES] Selected path: %s", pathInDistribution))
+		err = copyToLocation(ctx, filename, pathInDistribution, isDir, allFilesMap, rootNode, updateDescriptor, opts, result)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// This function will copy the given file or all files in the given directory to the given location in the distribution.
+// Files which have the same MD5 sum as the file in the distribution are not copied unless the MD5 check is disabled.
+func copyToLocation(ctx *runContext, filename, pathInDistribution string, isDir bool, allFilesMap map[string]data, rootNode *node, updateDescriptor *util.UpdateDescriptor, opts *CreateOptions, result *Result) error {
+	updateRoot := ctx.updateRoot
+	filesToCopy := []string{filename}
+	if isDir {
+		// If we are processing a directory, get all matching files. By matching files, we mean all the files
+		// which are in the directory and subdirectories.
+		filesToCopy = getAllMatchingFiles(filename, allFilesMap)
+		logger.Debug(fmt.Sprintf("All matches: %s", filesToCopy))
+	}
+	for _, match := range filesToCopy {
+		logger.Debug(fmt.Sprintf("match: %s", match))
+		// Check md5 only if the md5 checking is not disabled
+		if !opts.CheckMd5Disabled {
+			logger.Debug(fmt.Sprintf("Checking md5: %v", match))
+			data := allFilesMap[match]
+			// Check whether the md5 matches or not
+			fileLocation := path.Join(pathInDistribution, match)
+			md5Matches := CheckMD5(rootNode, strings.Split(fileLocation, "/"), data.md5)
+			if md5Matches {
+				result.addMessage(fmt.Sprintf("File '%v' not copied because MD5 matches with the already existing file.", match))
+				logger.Debug("MD5 matches. Ignoring file.")
+				continue
+			}
+			logger.Debug("MD5 does not match. Copying the file.")
+		}
+		// Copy the file to the staging directory
+		logger.Debug(fmt.Sprintf("[Copy] %s ; From: %s ; To: %s", match, updateRoot, pathInDistribution))
+		err := copyFile(ctx, match, updateRoot, pathInDistribution, rootNode, updateDescriptor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// This function will return all matching files (all files in a directory and subdirectories) of the given filepath.
+func getAllMatchingFiles(path string, allFilesMap map[string]data) []string {
+	matches := make([]string, 0)
+	for filePath, data := range allFilesMap {
+		// Should not be a directory. Should have the path prefix (identifying that it is in the directory)
+		// filePath != path because it should only return files within the provided directory. otherwise a file
+		// can be matched if it has the same path as the given path.
+		if !data.isDir && strings.HasPrefix(filePath, path) && filePath != path {
+			matches = append(matches, filePath)
+		}
+	}
+	return matches
+}
+
+// This function will read the directory in the given location and return 3 values and an error if any exists. Files and
+// directories which match the ignore patterns are skipped and a message is added for each of them.
+func readDirectory(root string, ignoredFiles *util.IgnorePatterns, ignorePatterns *util.IgnorePatterns, result *Result) (map[string]data, map[string]bool, map[string]bool, error) {
+	allFilesMap := make(map[string]data)
+	rootLevelDirectoriesMap := make(map[string]bool)
+	rootLevelFilesMap := make(map[string]bool)
+
+	// Walk and read the directory structure
+	err := filepath.Walk(root, func(absolutePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		//Convert all backslashes to slashes (to fix path issues in windows)
+		absolutePath = filepath.ToSlash(absolutePath)
+
+		//Ignore root directory
+		if root == absolutePath {
+			return nil
+		}
+		logger.Trace(fmt.Sprintf("[WALK] %s ; %v", absolutePath, fileInfo.IsDir()))
+		// Get the relative path. This is used as the key of the map
+		relativePath := strings.TrimPrefix(absolutePath, root + "/")
+		//check current file in ignored files. This is useful to ignore update-descriptor.yaml, etc in update directory
+		if ignoredFiles.IsIgnored(relativePath, fileInfo.IsDir()) {
+			if fileInfo.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		//check current file in the ignore patterns. This is useful to ignore files like .DS_Store, .git, etc
+		if ignorePatterns.IsIgnored(relativePath, fileInfo.IsDir()) {
+			result.addMessage(fmt.Sprintf("Junk file '%s' is not added to the update.", relativePath))
+			if fileInfo.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Create the data struct which will have the other details
+		info := data{
+			name: fileInfo.Name(),
+			relativePath: relativePath,
+		}
+		if fileInfo.IsDir() {
+			logger.Trace(fmt.Sprintf("Directory: %s , %s", absolutePath, fileInfo.Name()))
+			info.isDir = true
+			logger.Debug(fmt.Sprintf("Checking: %s == %s", path.Join(root, fileInfo.Name()), absolutePath))
+			// We need to only get the list of directories in the root level. Ignore other directories
+			if path.Join(root, fileInfo.Name()) == absolutePath {
+				logger.Debug(fmt.Sprintf("Paths are eqal. Adding %s to rootLevelDirectoriesMap", fileInfo.Name()))
+				// Add the entry to the rootLevelDirectoriesMap
+				rootLevelDirectoriesMap[fileInfo.Name()] = true
+			}
+		} else {
+			// We need to only get the list of files in the root level. Ignore other files
+			if path.Join(root, fileInfo.Name()) == absolutePath {
+				rootLevelFilesMap[fileInfo.Name()] = false
+			}
+
+			// We need other information like md5 sum because we are storing details of all files in the allFilesMap
+			logger.Trace("[MD5] Calculating MD5")
+			//If it is a file, calculate md5 sum
+			md5Sum, err := util.GetMD5(absolutePath)
+			if err != nil {
+				return err
+			}
+			logger.Trace(fmt.Sprintf("%s : %s = %s", absolutePath, fileInfo.Name(), md5Sum))
+			info.md5 = md5Sum
+			info.isDir = false
+		}
+		// Add the entry to the allFilesMap
+		allFilesMap[relativePath] = info
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// Remove the directories in the root level which do not have any files. Ex: directories which only have ignored
+	// files
+	for directoryName := range rootLevelDirectoriesMap {
+		hasFiles := false
+		for relativePath, info := range allFilesMap {
+			if !info.isDir && strings.HasPrefix(relativePath, directoryName + "/") {
+				hasFiles = true
+				break
+			}
+		}
+		if !hasFiles {
+			logger.Debug(fmt.Sprintf("Skipping '%s' because it does not have any files.", directoryName))
+			delete(rootLevelDirectoriesMap, directoryName)
+		}
+	}
+	return allFilesMap, rootLevelDirectoriesMap, rootLevelFilesMap, nil
+}
+
+// This function will read the zip file in the given location.
+func readZip(ctx *runContext, location string) (node, error) {
+	rootNode := createNewNode()
+	fileMap := make(map[string]bool)
+	// Create a reader out of the zip archive
+	zipReader, err := zip.OpenReader(location)
+	if err != nil {
+		return rootNode, err
+	}
+	defer zipReader.Close()
+
+	productName := ctx.productName
+	logger.Debug(fmt.Sprintf("productName: %s", productName))
+	// Iterate through each file in the zip file
+	for _, file := range zipReader.Reader.File {
+		zippedFile, err := file.Open()
+		if err != nil {
+			return rootNode, err
+		}
+		data, err := ioutil.ReadAll(zippedFile)
+		// Don't use defer here because otherwise there will be too many open files and it will cause a panic
+		zippedFile.Close()
+
+		// Calculate the md5 of the file
+		hash := md5.New()
+		hash.Write(data)
+		md5Hash := hex.EncodeToString(hash.Sum(nil))
+
+		// Get the relative path of the file
+		logger.Trace(fmt.Sprintf("file.Name: %s", file.Name))
+		relativePath := strings.TrimPrefix(file.Name, productName + "/")
+		// Replace all \ with /. Otherwise it will cause issues in Windows OS.
+		relativePath = filepath.ToSlash(relativePath)
+		logger.Trace(fmt.Sprintf("relativePath: %s", relativePath))
+
+		// Add the file to root node
+		AddToRootNode(&rootNode, strings.Split(relativePath, "/"), file.FileInfo().IsDir(), md5Hash)
+		if !file.FileInfo().IsDir() {
+			fileMap[relativePath] = false
+		}
+	}
+	return rootNode, nil
+}
+
+// This function will add a new node.
+func AddToRootNode(root *node, path []string, isDir bool, md5Hash string) *node {
+	logger.Trace(fmt.Sprintf("Checking: %s : %s", path[0], path))
+
+	// If the current path element is the last element, add it as a new node.
+	if len(path) == 1 {
+		logger.Trace("End reached")
+		newNode := createNewNode()
+		newNode.name = path[0]
+		newNode.isDir = isDir
+		newNode.md5Hash = md5Hash
+		if len(root.relativeLocation) == 0 {
+			newNode.relativeLocation = path[0]
+		} else {
+			newNode.relativeLocation = root.relativeLocation + "/" + path[0]
+		}
+		newNode.parent = root
+		root.childNodes[path[0]] = &newNode
+	} else {
+		// If there are more path elements than 1, that means we are currently processing a directory.
+		logger.Trace(fmt.Sprintf("End not reached. checking: %v", path[0]))
+		node, contains := root.childNodes[path[0]]
+		// If the directory is already not in the tree, add it as a new node
+		if !contains {
+			logger.Trace(fmt.Sprintf("Creating new node: %v", path[0]))
+			newNode := createNewNode()
+			newNode.name = path[0]
+			newNode.isDir = true
+			if len(root.relativeLocation) == 0 {
+				newNode.relativeLocation = path[0]
+			} else {
+				newNode.relativeLocation = root.relativeLocation + "/" + path[0]
+			}
+			newNode.parent = root
+			root.childNodes[path[0]] = &newNode
+			node = &newNode
+		}
+		// Recursively call the function for the rest of the path elements.
+		AddToRootNode(node, path[1:], isDir, md5Hash)
+	}
+	return root
+}
+
+// This function is a helper function which calls NodeExists() and checks whether a node exists in the given path and the
+// type(file/dir) is correct.
+func PathExists(rootNode *node, relativePath string, isDir bool) bool {
+	return NodeExists(rootNode, strings.Split(relativePath, "/"), isDir)
+}
+
+// This function checks whether a node exists in the given path and the type(file/dir) is correct.
+func NodeExists(rootNode *node, path []string, isDir bool) bool {
+	logger.Trace(fmt.Sprintf("All: %v", rootNode.childNodes))
+	logger.Trace(fmt.Sprintf("Checking: %s", path[0]))
+	childNode, found := rootNode.childNodes[path[0]]
+	// If the path element is found, that means it is in the tree
+	if found {
+		// If there are more path elements than 1, continue recursively. Otherwise check whether it has the provided type(file/dir) and return.
+		logger.Trace(fmt.Sprintf("%s found", path[0]))
+		if len(path) > 1 {
+			return NodeExists(childNode, path[1:], isDir)
+		} else {
+			return childNode.isDir == isDir
+		}
+	}
+	// If the path element is not found, return false
+	logger.Trace(fmt.Sprintf("%s NOT found", path[0]))
+	return false
+}
+
+// This function will check the MD5 hash of the file in the provided path in the distribution with the provided hash.
+func CheckMD5(rootNode *node, path []string, md5 string) bool {
+	logger.Trace(fmt.Sprintf("All: %v", rootNode.childNodes))
+	logger.Trace(fmt.Sprintf("Checking: %s", path[0]))
+	childNode, found := rootNode.childNodes[path[0]]
+	// If the path element is found, that means it is in the tree
+	if found {
+		// If there are more path elements than 1, continue recursively. Otherwise check whether it has the given md5 or not and return.
+		logger.Trace(fmt.Sprintf("%s found", path[0]))
+		if len(path) > 1 {
+			return CheckMD5(childNode, path[1:], md5)
+		} else {
+			return childNode.isDir == false && childNode.md5Hash == md5
+		}
+	}
+	// If the path element is not found, return false
+	logger.Trace(fmt.Sprintf("%s NOT found", path[0]))
+	return false
+}
+
+// This function will find all matches in distribution for the provided name.
+func FindMatches(root *node, name string, isDir bool, matches map[string]*node) {
+	// Check whether the given name is in the child nodes
+	childNode, found := root.childNodes[name]
+	if found {
+		// If it is in child nodes, check whether the type matches
+		if isDir == childNode.isDir {
+			// If type matches, add it to the matches map
+			matches[root.relativeLocation] = root
+		}
+	}
+	// Regardless of whether the file is found or not, iterate through all sub directories to find all matches
+	for _, childNode := range root.childNodes {
+		if childNode.isDir {
+			FindMatches(childNode, name, isDir, matches)
+		}
+	}
+}
+
+// This function will save update descriptor after modifying the file_changes section.
+func saveUpdateDescriptor(ctx *runContext, updateDescriptorFilename string, data []byte) error {
+	destination := path.Join(ctx.stagingDirectory, ctx.updateName, updateDescriptorFilename)
+	// Open a new file for writing only
+	file, err := os.OpenFile(
+		destination,
+		os.O_WRONLY | os.O_TRUNC | os.O_CREATE,
+		0600,
+	)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	// Write bytes to file
+	_, err = file.Write(data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// This function will return an empty list if the given list is nil so that empty lists are saved as [] in the
+// update-descriptor.yaml.
+func getNonNilList(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+// This function will copy resource files to the staging directory. Files are copied to the same location relative to the
+// root of the update. An error is returned if a mandatory pattern does not match any file. Optional patterns which do
+// not match any file are reported in a single message.
+func copyResourceFilesToTempDir(ctx *runContext, resourceFiles ResourceFiles, ignorePatterns *util.IgnorePatterns, result *Result) error {
+	// Create the directories if they are not available
+	destination := path.Join(ctx.stagingDirectory, ctx.updateName, constant.CARBON_HOME)
+	util.CreateDirectory(destination)
+
+	resourceFilePaths, missingMandatoryFiles, missingOptionalFiles, err := findResourceFiles(ctx.updateRoot, resourceFiles, ignorePatterns)
+	if err != nil {
+		return err
+	}
+	if len(missingMandatoryFiles) > 0 {
+		return errors.New(fmt.Sprintf("Mandatory resource files not found: %s", strings.Join(missingMandatoryFiles, ", ")))
+	}
+	// Iterate through all resource files
+	for _, relativePath := range resourceFilePaths {
+		source := path.Join(ctx.updateRoot, relativePath)
+		destination := path.Join(ctx.stagingDirectory, ctx.updateName, relativePath)
+		err := util.CreateDirectory(path.Dir(destination))
+		if err != nil {
+			return err
+		}
+		// Copy the file
+		err = util.CopyFile(source, destination)
+		if err != nil {
+			return err
+		}
+	}
+	if len(missingOptionalFiles) > 0 {
+		result.addMessage(fmt.Sprintf("Optional resource files not found in the update directory: %s",
+			strings.Join(missingOptionalFiles, ", ")))
+	}
+	return nil
+}
+
+// This function will return the paths of the files in the given update directory which match the mandatory or the
+// optional resource file patterns, and the mandatory and the optional patterns which do not match any file. Paths are
+// relative to the update directory. Files which match the ignore patterns are skipped.
+func findResourceFiles(updateRoot string, resourceFiles ResourceFiles, ignorePatterns *util.IgnorePatterns) ([]string, []string, []string, error) {
+	resourceFilePaths := make([]string, 0)
+	matchedPatterns := make(map[string]bool)
+	patterns := append(append([]string{}, resourceFiles.Mandatory...), resourceFiles.Optional...)
+	root := filepath.ToSlash(updateRoot)
+	err := filepath.Walk(updateRoot, func(absolutePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath := strings.TrimPrefix(filepath.ToSlash(absolutePath), root + "/")
+		if filepath.ToSlash(absolutePath) == root {
+			return nil
+		}
+		if ignorePatterns.IsIgnored(relativePath, fileInfo.IsDir()) {
+			if fileInfo.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fileInfo.IsDir() {
+			return nil
+		}
+		isResourceFile := false
+		for _, pattern := range patterns {
+			if matchesResourcePattern(pattern, relativePath) {
+				matchedPatterns[pattern] = true
+				isResourceFile = true
+			}
+		}
+		if isResourceFile {
+			resourceFilePaths = append(resourceFilePaths, relativePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	logger.Debug(fmt.Sprintf("resourceFilePaths: %v", resourceFilePaths))
+
+	missingMandatoryFiles := make([]string, 0)
+	for _, pattern := range resourceFiles.Mandatory {
+		if !matchedPatterns[pattern] {
+			missingMandatoryFiles = append(missingMandatoryFiles, pattern)
+		}
+	}
+	missingOptionalFiles := make([]string, 0)
+	for _, pattern := range resourceFiles.Optional {
+		if !matchedPatterns[pattern] {
+			missingOptionalFiles = append(missingOptionalFiles, pattern)
+		}
+	}
+	return resourceFilePaths, missingMandatoryFiles, missingOptionalFiles, nil
+}
+
+//This function will copy the file/directory from update to the staging directory.
+func copyFile(ctx *runContext, filename string, locationInUpdate, relativeLocationInTemp string, rootNode *node, updateDescriptor *util.UpdateDescriptor) error {
+	logger.Debug(fmt.Sprintf("[FINAL][COPY ROOT] Name: %s ; IsDir: false ; From: %s ; To: %s", filename, locationInUpdate, relativeLocationInTemp))
+	source := path.Join(locationInUpdate, filename)
+	carbonHome := path.Join(ctx.stagingDirectory, ctx.updateName, constant.CARBON_HOME)
+	destination := path.Join(carbonHome, relativeLocationInTemp)
+
+	//Replace all / with OS specific path separators to handle OSs like Windows
+	destination = strings.Replace(destination, "/", constant.PATH_SEPARATOR, -1)
+
+	fullPath := path.Join(destination, filename)
+	//Replace all / with OS specific path separators to handle OSs like Windows
+	fullPath = strings.Replace(fullPath, "/", constant.PATH_SEPARATOR, -1)
+
+	parentDirectory := path.Dir(fullPath)
+	logger.Debug("parentDirectory:", parentDirectory)
+	err := util.CreateDirectory(parentDirectory)
+	if err != nil {
+		return newError(opCreate, err, fmt.Sprintf("Error occurred while creating '%v' directory.", parentDirectory))
+	}
+	logger.Debug(fmt.Sprintf("[FINAL][COPY][TEMP] Name: %s; From: %s; To: %s", filename, source, fullPath))
+	err = util.CopyFile(source, fullPath)
+	if err != nil {
+		return newError(opCreate, err, fmt.Sprintf("Error occurred while copying file. Source: %v, Destination: %v", source, fullPath))
+	}
+
+	prefix := carbonHome + "/"
+	// Replace all / characters with the os path separator character. Otherwise errors will occur in OSs like Windows
+	prefix = strings.Replace(prefix, "/", constant.PATH_SEPARATOR, -1)
+	logger.Debug(fmt.Sprintf("Trimming %s using %s", fullPath, prefix))
+	relativePath := strings.TrimPrefix(fullPath, prefix)
+	logger.Debug(fmt.Sprintf("relativePath: %s", relativePath))
+	contains := PathExists(rootNode, relativePath, false)
+	logger.Debug(fmt.Sprintf("contains: %v", contains))
+	// If the file already in the distribution, add it as a modified file. Otherwise add it as a new file
+	if contains {
+		updateDescriptor.File_changes.Modified_files = append(updateDescriptor.File_changes.Modified_files, relativePath)
+	} else {
+		updateDescriptor.File_changes.Added_files = append(updateDescriptor.File_changes.Added_files, relativePath)
+	}
+	return nil
+}
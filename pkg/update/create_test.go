@@ -1,8 +1,11 @@
 // Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
 
-package cmd
+package update
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -24,6 +27,37 @@ func TestGetUpdateName(t *testing.T) {
 	}
 }
 
+func TestGetUpdateZipPath(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	updateName := "WSO2-CARBON-UPDATE-4.4.0-0001"
+	testCases := []struct {
+		outputLocation string
+		expected       string
+	}{
+		{"", updateName + ".zip"},
+		{filepath.Join(root, "out"), filepath.Join(root, "out", updateName + ".zip")},
+		{filepath.Join(root, "dist", "update.zip"), filepath.Join(root, "dist", "update.zip")},
+	}
+	for _, testCase := range testCases {
+		actual, err := getUpdateZipPath(testCase.outputLocation, updateName)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if actual != testCase.expected {
+			t.Errorf("Test failed, expected: %s, actual: %s", testCase.expected, actual)
+		}
+		exists, err := util.IsDirectoryExists(filepath.Dir(actual))
+		if err != nil || !exists {
+			t.Errorf("Test failed, directory '%s' not created.", filepath.Dir(actual))
+		}
+	}
+}
+
 func TestAddToRootNode(t *testing.T) {
 	//Add new file
 	isDir := false
@@ -165,3 +199,43 @@ func TestPathExists(t *testing.T) {
 		t.Errorf("Test failed, expected: %v, actual: %v", expected, exists)
 	}
 }
+
+func TestReadDirectoryWithResourcePatterns(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{"README.md", "repository/components/foo/README.md", "docs/guide.txt", "lib/a.jar"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0700); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(name), 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+	resourceFiles := ResourceFiles{
+		Optional: []string{"*.md"},
+		Skip: []string{"docs/"},
+	}
+
+	// Only the files copied as resource files and the skipped files should be ignored
+	allFilesMap, _, _, err := readDirectory(filepath.ToSlash(root), resourceFiles.getIgnoredFiles(), nil, &Result{})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	for name, expected := range map[string]bool{
+		"README.md": false,
+		"repository/components/foo/README.md": true,
+		"docs/guide.txt": false,
+		"lib/a.jar": true,
+	} {
+		if _, found := allFilesMap[name]; found != expected {
+			t.Errorf("Test failed for '%s', expected: %v, actual: %v", name, expected, found)
+		}
+		if resourceFiles.isResourceFile(name) != (name == "README.md") {
+			t.Errorf("Test failed. Unexpected resource file match for '%s'", name)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// CheckDescriptor will find the placeholders in the update-descriptor.yaml of the given update directory. The found
+// placeholders are always returned. If placeholders are found and they are not allowed, an error with the
+// CodePlaceholderFound code is returned as well.
+func CheckDescriptor(updateDirectory string, allowPlaceholders bool) ([]util.Placeholder, error) {
+	updateDescriptorPath := filepath.Join(updateDirectory, constant.UPDATE_DESCRIPTOR_FILE)
+	data, err := ioutil.ReadFile(updateDescriptorPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, newError(opDescriptor, nil, fmt.Sprintf("'%s' not found at '%s' directory.",
+				constant.UPDATE_DESCRIPTOR_FILE, updateDirectory))
+		}
+		return nil, newError(opDescriptor, err, fmt.Sprintf("Error occurred when reading '%s' file.",
+			constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	placeholders, err := util.FindPlaceholders(data)
+	if err != nil {
+		return nil, newErrorWithCode(opDescriptor, CodeDescriptorInvalid, err, "'" + constant.UPDATE_DESCRIPTOR_FILE + "' is invalid.")
+	}
+	if len(placeholders) > 0 && !allowPlaceholders {
+		return placeholders, newErrorWithCode(opDescriptor, CodePlaceholderFound, nil, fmt.Sprintf("%d placeholder(s) found in '%s'.",
+			len(placeholders), updateDescriptorPath))
+	}
+	return placeholders, nil
+}
+
+// struct which is used to return a field of an update-descriptor.yaml and its value
+type DescriptorField struct {
+	Name  string
+	Value string
+}
+
+// Fields which can be changed using SetDescriptorField. security_severity is only available in version 2 descriptors.
+var settableDescriptorFields = []string{"update_number", "platform_version", "platform_name", "applies_to",
+	"description", "security_severity", "requires", "supersedes"}
+
+// This is used to hold the update-descriptor.yaml of an update directory while editing it. Only one of the
+// descriptors is set depending on the descriptor version of the file.
+type descriptorFile struct {
+	path               string
+	// Content of the file when it was loaded
+	data               []byte
+	// Fields which are changed after loading the file
+	changedFields      []string
+	updateDescriptor   *util.UpdateDescriptor
+	updateDescriptorV2 *util.UpdateDescriptorV2
+}
+
+// GetSettableDescriptorFields will return the fields which can be changed using SetDescriptorField.
+func GetSettableDescriptorFields() []string {
+	return append([]string{}, settableDescriptorFields...)
+}
+
+// SetDescriptorField will set the value of the given field in the update-descriptor.yaml of the given update directory.
+// The value is validated before saving the file. Lists such as applies_to in version 2 descriptors, requires and
+// supersedes are given as comma separated values. An empty value removes an optional field.
+func SetDescriptorField(updateDirectory, field, value string) (*Result, error) {
+	if !util.IsStringIsInSlice(field, settableDescriptorFields) {
+		return nil, newError(opDescriptor, nil, fmt.Sprintf("'%s' field cannot be set. Supported fields are %s.", field,
+			strings.Join(settableDescriptorFields, ", ")))
+	}
+	return editDescriptor(updateDirectory, func(descriptor *descriptorFile, result *Result) error {
+		value = strings.TrimSpace(value)
+		var err error
+		switch field {
+		case "update_number":
+			err = util.ValidateUpdateNumber(value)
+		case "platform_version":
+			err = util.ValidatePlatformVersion(value)
+		case "platform_name":
+			err = util.ValidatePlatformName(value)
+		case "applies_to":
+			err = util.ValidateAppliesTo(value)
+		case "description":
+			err = util.ValidateDescription(value)
+		case "security_severity":
+			if descriptor.updateDescriptorV2 == nil {
+				return errors.New(fmt.Sprintf("'%s' is only supported in version %s descriptors. Run 'wum-uc descriptor migrate' to migrate the descriptor.",
+					field, constant.DESCRIPTOR_VERSION_2))
+			}
+			if len(value) > 0 {
+				err = util.ValidateSecuritySeverity(value)
+			}
+		case "requires":
+			err = util.ValidateUpdateDependencies(descriptor.getUpdateNumber(), getList(value), []string{})
+		case "supersedes":
+			err = util.ValidateUpdateDependencies(descriptor.getUpdateNumber(), []string{}, getList(value))
+		}
+		if err != nil {
+			return err
+		}
+		descriptor.setField(field, value)
+		result.addMessage(fmt.Sprintf("'%s' set to '%s'.", field, value))
+		return nil
+	})
+}
+
+// AddBugFix will add the given JIRA key and its summary to the bug_fixes field of the update-descriptor.yaml of the
+// given update directory. If the summary is empty, it is fetched from the JIRA. Placeholder and 'N/A' entries are
+// removed when a bug fix is added.
+func AddBugFix(updateDirectory, key, summary string) (*Result, error) {
+	return editDescriptor(updateDirectory, func(descriptor *descriptorFile, result *Result) error {
+		key = strings.TrimSpace(key)
+		if len(key) == 0 {
+			return errors.New("JIRA key cannot be empty.")
+		}
+		summary = strings.TrimSpace(summary)
+		if len(summary) == 0 {
+			summary = util.GetJiraSummary(key)
+		}
+		bugFixes := descriptor.getBugFixes()
+		for _, entry := range []string{constant.JIRA_KEY_DEFAULT, constant.JIRA_NA} {
+			if _, found := bugFixes[entry]; found && entry != key {
+				delete(bugFixes, entry)
+				result.addMessage(fmt.Sprintf("'%s' entry removed from 'bug_fixes'.", entry))
+			}
+		}
+		descriptor.setChanged("bug_fixes")
+		if _, found := bugFixes[key]; found {
+			result.addMessage(fmt.Sprintf("Summary of '%s' updated.", key))
+		} else {
+			result.addMessage(fmt.Sprintf("'%s' added to 'bug_fixes'.", key))
+		}
+		bugFixes[key] = summary
+		return nil
+	})
+}
+
+// RemoveBugFix will remove the given JIRA key from the bug_fixes field of the update-descriptor.yaml of the given
+// update directory. If no bug fixes are left, 'N/A: N/A' is added because the bug_fixes field cannot be empty.
+func RemoveBugFix(updateDirectory, key string) (*Result, error) {
+	return editDescriptor(updateDirectory, func(descriptor *descriptorFile, result *Result) error {
+		key = strings.TrimSpace(key)
+		bugFixes := descriptor.getBugFixes()
+		if _, found := bugFixes[key]; !found {
+			return errors.New(fmt.Sprintf("'%s' not found in 'bug_fixes'.", key))
+		}
+		delete(bugFixes, key)
+		descriptor.setChanged("bug_fixes")
+		result.addMessage(fmt.Sprintf("'%s' removed from 'bug_fixes'.", key))
+		if len(bugFixes) == 0 {
+			bugFixes[constant.JIRA_NA] = constant.JIRA_NA
+			result.addMessage(fmt.Sprintf("No bug fixes left. '%s: %s' added to 'bug_fixes'.", constant.JIRA_NA,
+				constant.JIRA_NA))
+		}
+		return nil
+	})
+}
+
+// ShowDescriptor will return the fields of the update-descriptor.yaml of the given update directory in the same order
+// they are saved. Bug fixes are returned as separate fields. If the descriptor is not valid, a warning is added to
+// the result.
+func ShowDescriptor(updateDirectory string) ([]DescriptorField, *Result, error) {
+	result := &Result{}
+	descriptor, err := loadDescriptorFile(updateDirectory)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.OutputPath = descriptor.path
+	if err := descriptor.validate(); err != nil {
+		result.addWarning(fmt.Sprintf("'%s' is not valid. %s", constant.UPDATE_DESCRIPTOR_FILE, err.Error()))
+	}
+	return descriptor.getFields(), result, nil
+}
+
+// This function will load the update-descriptor.yaml, call the given function to change it and save it. The whole
+// descriptor is validated after the change. The file is saved even if it is not valid so that the fields can be
+// changed one at a time. But a warning is added to the result.
+func editDescriptor(updateDirectory string, edit func(descriptor *descriptorFile, result *Result) error) (*Result, error) {
+	result := &Result{}
+	descriptor, err := loadDescriptorFile(updateDirectory)
+	if err != nil {
+		return nil, err
+	}
+	if err := edit(descriptor, result); err != nil {
+		return nil, newErrorWithCode(opDescriptor, CodeDescriptorInvalid, err, "")
+	}
+	if err := descriptor.save(); err != nil {
+		return nil, newError(opDescriptor, err, fmt.Sprintf("Error occurred while saving '%s'.", descriptor.path))
+	}
+	result.OutputPath = descriptor.path
+	if err := descriptor.validate(); err != nil {
+		result.addWarning(fmt.Sprintf("'%s' is not valid. %s", constant.UPDATE_DESCRIPTOR_FILE, err.Error()))
+	}
+	return result, nil
+}
+
+// This function will load the update-descriptor.yaml in the given update directory using the struct of its descriptor
+// version.
+func loadDescriptorFile(updateDirectory string) (*descriptorFile, error) {
+	descriptor := &descriptorFile{
+		path: filepath.Join(updateDirectory, constant.UPDATE_DESCRIPTOR_FILE),
+	}
+	exists, err := util.IsFileExists(descriptor.path)
+	if err != nil {
+		return nil, newError(opDescriptor, err, fmt.Sprintf("Error occurred while checking '%s'", descriptor.path))
+	}
+	if !exists {
+		return nil, newError(opDescriptor, nil, fmt.Sprintf("'%s' not found at '%s' directory.",
+			constant.UPDATE_DESCRIPTOR_FILE, updateDirectory))
+	}
+	descriptor.data, err = ioutil.ReadFile(descriptor.path)
+	if err != nil {
+		return nil, newError(opDescriptor, err, fmt.Sprintf("Error occurred when reading '%s' file.",
+			constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	descriptorVersion, err := util.GetDescriptorVersion(descriptor.data)
+	if err != nil {
+		return nil, newErrorWithCode(opDescriptor, CodeDescriptorInvalid, err, fmt.Sprintf("Error occurred when reading '%s' file.",
+			constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	if descriptorVersion == constant.DESCRIPTOR_VERSION_2 {
+		descriptor.updateDescriptorV2, err = util.LoadUpdateDescriptorV2(constant.UPDATE_DESCRIPTOR_FILE, updateDirectory)
+	} else {
+		descriptor.updateDescriptor, err = util.LoadUpdateDescriptor(constant.UPDATE_DESCRIPTOR_FILE, updateDirectory)
+	}
+	if err != nil {
+		return nil, newErrorWithCode(opDescriptor, CodeDescriptorInvalid, err, fmt.Sprintf("Error occurred when reading '%s' file.",
+			constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	return descriptor, nil
+}
+
+// This function will save the changed fields of the descriptor. The descriptor is marshalled using the marshal
+// function of its descriptor version so that the values have the correct quoting. Then only the changed fields are
+// set in the loaded content so that the comments and the order of the keys are kept as the author wrote them.
+func (descriptor *descriptorFile) save() error {
+	var updatedData []byte
+	var err error
+	if descriptor.updateDescriptorV2 != nil {
+		updatedData, err = util.MarshalUpdateDescriptorV2(descriptor.updateDescriptorV2)
+	} else {
+		updatedData, err = util.MarshalUpdateDescriptor(descriptor.updateDescriptor)
+	}
+	if err != nil {
+		return err
+	}
+	data, err := util.SetDescriptorFields(descriptor.data, updatedData, descriptor.changedFields)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(descriptor.path, data, 0600)
+}
+
+// This function will mark the given field as changed so that it is saved.
+func (descriptor *descriptorFile) setChanged(field string) {
+	if !util.IsStringIsInSlice(field, descriptor.changedFields) {
+		descriptor.changedFields = append(descriptor.changedFields, field)
+	}
+}
+
+// This function will validate the descriptor using the validate function of its descriptor version.
+func (descriptor *descriptorFile) validate() error {
+	if descriptor.updateDescriptorV2 != nil {
+		return util.ValidateUpdateDescriptorV2(descriptor.updateDescriptorV2)
+	}
+	return util.ValidateUpdateDescriptor(descriptor.updateDescriptor)
+}
+
+// This function will return the update number of the descriptor.
+func (descriptor *descriptorFile) getUpdateNumber() string {
+	if descriptor.updateDescriptorV2 != nil {
+		return descriptor.updateDescriptorV2.UpdateNumber
+	}
+	return descriptor.updateDescriptor.Update_number
+}
+
+// This function will return the bug fixes of the descriptor. The map is created if it is not available so that the
+// returned map can be changed.
+func (descriptor *descriptorFile) getBugFixes() map[string]string {
+	if descriptor.updateDescriptorV2 != nil {
+		if descriptor.updateDescriptorV2.BugFixes == nil {
+			descriptor.updateDescriptorV2.BugFixes = make(map[string]string)
+		}
+		return descriptor.updateDescriptorV2.BugFixes
+	}
+	if descriptor.updateDescriptor.Bug_fixes == nil {
+		descriptor.updateDescriptor.Bug_fixes = make(map[string]string)
+	}
+	return descriptor.updateDescriptor.Bug_fixes
+}
+
+// This function will set the given field of the descriptor. The value should be validated before calling this
+// function.
+func (descriptor *descriptorFile) setField(field, value string) {
+	descriptor.setChanged(field)
+	if updateDescriptor := descriptor.updateDescriptorV2; updateDescriptor != nil {
+		switch field {
+		case "update_number":
+			updateDescriptor.UpdateNumber = value
+		case "platform_version":
+			updateDescriptor.PlatformVersion = value
+		case "platform_name":
+			updateDescriptor.PlatformName = value
+		case "applies_to":
+			updateDescriptor.AppliesTo = util.GetProductsInAppliesTo(value)
+		case "description":
+			updateDescriptor.Description = value
+		case "security_severity":
+			updateDescriptor.SecuritySeverity = value
+		case "requires":
+			updateDescriptor.Requires = getList(value)
+		case "supersedes":
+			updateDescriptor.Supersedes = getList(value)
+		}
+		return
+	}
+	updateDescriptor := descriptor.updateDescriptor
+	switch field {
+	case "update_number":
+		updateDescriptor.Update_number = value
+	case "platform_version":
+		updateDescriptor.Platform_version = value
+	case "platform_name":
+		updateDescriptor.Platform_name = value
+	case "applies_to":
+		updateDescriptor.Applies_to = value
+	case "description":
+		updateDescriptor.Description = value
+	case "requires":
+		updateDescriptor.Requires = getList(value)
+	case "supersedes":
+		updateDescriptor.Supersedes = getList(value)
+	}
+}
+
+// This function will return the fields of the descriptor in the order they are saved.
+func (descriptor *descriptorFile) getFields() []DescriptorField {
+	fields := make([]DescriptorField, 0)
+	updateDescriptorV1 := descriptor.updateDescriptor
+	if updateDescriptor := descriptor.updateDescriptorV2; updateDescriptor != nil {
+		fields = append(fields, DescriptorField{"descriptor_version", updateDescriptor.DescriptorVersion})
+		updateDescriptorV1 = util.ConvertToUpdateDescriptorV1(updateDescriptor)
+	}
+	fields = append(fields,
+		DescriptorField{"update_number", updateDescriptorV1.Update_number},
+		DescriptorField{"platform_version", updateDescriptorV1.Platform_version},
+		DescriptorField{"platform_name", updateDescriptorV1.Platform_name},
+		DescriptorField{"applies_to", updateDescriptorV1.Applies_to},
+	)
+	if descriptor.updateDescriptorV2 != nil && len(descriptor.updateDescriptorV2.SecuritySeverity) > 0 {
+		fields = append(fields, DescriptorField{"security_severity", descriptor.updateDescriptorV2.SecuritySeverity})
+	}
+	if len(updateDescriptorV1.Requires) > 0 {
+		fields = append(fields, DescriptorField{"requires", strings.Join(updateDescriptorV1.Requires, ", ")})
+	}
+	if len(updateDescriptorV1.Supersedes) > 0 {
+		fields = append(fields, DescriptorField{"supersedes", strings.Join(updateDescriptorV1.Supersedes, ", ")})
+	}
+	keys := make([]string, 0)
+	for key := range updateDescriptorV1.Bug_fixes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, DescriptorField{"bug_fixes." + key, updateDescriptorV1.Bug_fixes[key]})
+	}
+	fields = append(fields, DescriptorField{"description", strings.TrimSpace(updateDescriptorV1.Description)})
+	return fields
+}
+
+// This function will convert the given comma separated values to a list. Empty values are ignored.
+func getList(value string) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+func TestEditDescriptor(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	updateDescriptorPath := filepath.Join(root, constant.UPDATE_DESCRIPTOR_FILE)
+	err = ioutil.WriteFile(updateDescriptorPath, []byte(`update_number: 0001
+platform_version: 4.4.0
+# Name of the platform
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  ADD_JIRA_KEY_HERE: ADD_JIRA_SUMMARY_HERE
+description: Fixes something.
+`), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	if _, err := SetDescriptorField(root, "update_number", "12"); GetErrorCode(err) != CodeDescriptorInvalid {
+		t.Errorf("Test failed, expected: %s, actual: %v", CodeDescriptorInvalid, err)
+	}
+	if _, err := SetDescriptorField(root, "security_severity", "high"); err == nil {
+		t.Error("Test failed. Expected an error when setting security_severity in a version 1 descriptor")
+	}
+	if _, err := SetDescriptorField(root, "update_number", "0002"); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if _, err := SetDescriptorField(root, "description", "Fixes: \"quoted\" values."); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if _, err := AddBugFix(root, "CARBON-1", "Fix something"); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if _, err := RemoveBugFix(root, "CARBON-2"); err == nil {
+		t.Error("Test failed. Expected an error when removing an unknown bug fix")
+	}
+
+	data, err := ioutil.ReadFile(updateDescriptorPath)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	expected := `update_number: 0002
+platform_version: 4.4.0
+# Name of the platform
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  CARBON-1: Fix something
+description: 'Fixes: "quoted" values.'
+`
+	if !strings.HasPrefix(string(data), expected) {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, string(data))
+	}
+
+	result, err := RemoveBugFix(root, "CARBON-1")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("Test failed. Unexpected warnings: %v", result.Warnings)
+	}
+	updateDescriptor, err := util.LoadUpdateDescriptor(constant.UPDATE_DESCRIPTOR_FILE, root)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if updateDescriptor.Bug_fixes[constant.JIRA_NA] != constant.JIRA_NA || len(updateDescriptor.Bug_fixes) != 1 {
+		t.Errorf("Test failed. Unexpected bug fixes: %v", updateDescriptor.Bug_fixes)
+	}
+}
+
+func TestSetDescriptorFieldInVersion2Descriptor(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	err = ioutil.WriteFile(filepath.Join(root, constant.UPDATE_DESCRIPTOR_FILE), []byte(`descriptor_version: "2"
+update_number: "0001"
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to:
+- wso2esb-4.9.0
+bug_fixes:
+  CARBON-1: Fix something
+description: Fixes something.
+`), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	if _, err := SetDescriptorField(root, "applies_to", "wso2esb-4.9.0, wso2am-1.10.0"); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if _, err := SetDescriptorField(root, "security_severity", "high"); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	updateDescriptor, err := util.LoadUpdateDescriptorV2(constant.UPDATE_DESCRIPTOR_FILE, root)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(updateDescriptor.AppliesTo) != 2 || updateDescriptor.AppliesTo[1] != "wso2am-1.10.0" {
+		t.Errorf("Test failed. Unexpected applies_to: %v", updateDescriptor.AppliesTo)
+	}
+	if updateDescriptor.SecuritySeverity != "high" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "high", updateDescriptor.SecuritySeverity)
+	}
+	if err := util.ValidateUpdateDescriptorV2(updateDescriptor); err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ErrorCode is a stable code which identifies the class of an error. Codes are never changed or reused so that they can
+// be used in scripts, reports and documentation.
+type ErrorCode string
+
+// Codes of the failure classes.
+const (
+	CodeDescriptorInvalid ErrorCode = "UC-VAL-001"
+	CodeUnknownFile ErrorCode = "UC-VAL-002"
+	CodeFileNotInDistribution ErrorCode = "UC-VAL-003"
+	CodeMisplacedResourceFile ErrorCode = "UC-VAL-004"
+	CodeBadFilename ErrorCode = "UC-VAL-005"
+	CodeDistributionUnreadable ErrorCode = "UC-VAL-006"
+	CodeDuplicateUpdateNumber ErrorCode = "UC-VAL-007"
+	CodePlaceholderFound ErrorCode = "UC-VAL-008"
+	CodeInstructionsInvalid ErrorCode = "UC-VAL-009"
+	CodeConfDiffConflict ErrorCode = "UC-VAL-010"
+)
+
+// Errors which can be used with errors.Is to check the class of an error returned by the functions in this package.
+var (
+	ErrDescriptorInvalid = &Error{Code: CodeDescriptorInvalid}
+	ErrUnknownFile = &Error{Code: CodeUnknownFile}
+	ErrFileNotInDistribution = &Error{Code: CodeFileNotInDistribution}
+	ErrMisplacedResourceFile = &Error{Code: CodeMisplacedResourceFile}
+	ErrBadFilename = &Error{Code: CodeBadFilename}
+	ErrDistributionUnreadable = &Error{Code: CodeDistributionUnreadable}
+	ErrDuplicateUpdateNumber = &Error{Code: CodeDuplicateUpdateNumber}
+	ErrPlaceholderFound = &Error{Code: CodePlaceholderFound}
+	ErrInstructionsInvalid = &Error{Code: CodeInstructionsInvalid}
+	ErrConfDiffConflict = &Error{Code: CodeConfDiffConflict}
+)
+
+// Error is the type of all errors returned by the functions in this package.
+type Error struct {
+	// Operation which failed. Ex: create, validate
+	Op      string
+	// Class of the failure. This is empty for failures which do not belong to a known class such as IO errors.
+	Code    ErrorCode
+	// Description of the failure
+	Message string
+	// Underlying error if any
+	Err     error
+}
+
+// struct which is used to describe the cause and the fix of an error code
+type Explanation struct {
+	Code  ErrorCode
+	Title string
+	Cause string
+	Fix   string
+}
+
+var explanations = map[ErrorCode]Explanation{
+	CodeDescriptorInvalid: {
+		Code: CodeDescriptorInvalid,
+		Title: "Update descriptor is invalid",
+		Cause: "The 'update-descriptor.yaml' could not be parsed or one of its fields has an invalid value. Ex: the " +
+			"update number is not a 4 digit number, a product in 'applies_to' is not in the product catalog or a " +
+			"mandatory field is empty.",
+		Fix: "Correct the field mentioned in the error message. Run 'wum-uc init --sample' to view a valid " +
+			"'update-descriptor.yaml'.",
+	},
+	CodeUnknownFile: {
+		Code: CodeUnknownFile,
+		Title: "Unknown file in the update",
+		Cause: "The update zip contains a file or a directory which is neither inside the 'carbon.home' directory " +
+			"nor a known resource file.",
+		Fix: "Move the file inside the 'carbon.home' directory using the same directory structure as the " +
+			"distribution, or remove it from the update. Resource files are configured using the " +
+			"'resource_files' section of the config.yaml.",
+	},
+	CodeFileNotInDistribution: {
+		Code: CodeFileNotInDistribution,
+		Title: "File not found in the distribution",
+		Cause: "A file in the update does not exist at the same location in the distribution and it is not listed " +
+			"in the 'added_files' section of the 'update-descriptor.yaml'.",
+		Fix: "If the file is a new file, add its path relative to 'carbon.home' to the 'added_files' section. " +
+			"Otherwise, move it to the correct location or check whether the correct distribution was given.",
+	},
+	CodeMisplacedResourceFile: {
+		Code: CodeMisplacedResourceFile,
+		Title: "Resource file is in the wrong directory",
+		Cause: "A resource file such as 'update-descriptor.yaml', 'LICENSE.txt' or 'instructions.txt' was found " +
+			"in a directory other than the root directory of the update.",
+		Fix: "Move the file to the root directory of the update. Ex: WSO2-CARBON-UPDATE-4.4.0-0001/LICENSE.txt",
+	},
+	CodeBadFilename: {
+		Code: CodeBadFilename,
+		Title: "Update filename is invalid",
+		Cause: "The update is not a zip file or its filename does not match the WSO2-CARBON-UPDATE-<platform " +
+			"version>-<update number>.zip format.",
+		Fix: "Rename the update zip. Updates created by 'wum-uc create' always have a valid filename.",
+	},
+	CodeDistributionUnreadable: {
+		Code: CodeDistributionUnreadable,
+		Title: "Distribution cannot be read",
+		Cause: "The distribution does not exist, is not a zip file or the zip file is corrupted.",
+		Fix: "Check the distribution location and download the distribution again if it is corrupted.",
+	},
+	CodeDuplicateUpdateNumber: {
+		Code: CodeDuplicateUpdateNumber,
+		Title: "Duplicate update number",
+		Cause: "More than one update in the directory validated using 'validate --batch' has the same platform " +
+			"version and update number in the 'update-descriptor.yaml'.",
+		Fix: "Allocate a new update number for one of the updates and create it again, or remove the outdated " +
+			"update from the directory.",
+	},
+	CodePlaceholderFound: {
+		Code: CodePlaceholderFound,
+		Title: "Placeholder in update descriptor",
+		Cause: "A placeholder added by 'wum-uc init' such as 'ADD_JIRA_KEY_HERE' was not replaced in the " +
+			"'update-descriptor.yaml'.",
+		Fix: "Replace the placeholders in the fields and lines mentioned in the error message. Run 'wum-uc " +
+			"descriptor check' to list them. Use the --allow-placeholders flag to report them as warnings instead.",
+	},
+	CodeInstructionsInvalid: {
+		Code: CodeInstructionsInvalid,
+		Title: "Structured instructions are invalid",
+		Cause: "The 'instructions.yaml' could not be parsed, has an unknown field or an entry without a file, or a " +
+			"configuration file in 'config_changes' does not exist in the distribution.",
+		Fix: "Correct the entry mentioned in the error message. Files are relative to 'carbon.home'. If the " +
+			"configuration file is added by the update, add it to the 'added_files' section of the " +
+			"'update-descriptor.yaml'.",
+	},
+	CodeConfDiffConflict: {
+		Code: CodeConfDiffConflict,
+		Title: "Configuration diff does not apply",
+		Cause: "A value changed by the 'conf-diffs.yaml' of the update does not have the expected old value in the " +
+			"distribution, a value added by it already exists, or the configuration file is not in the distribution.",
+		Fix: "Check whether the correct distribution was given. Otherwise create the update again using the " +
+			"distribution so that the diffs are generated against its configuration files.",
+	},
+}
+
+func (e *Error) Error() string {
+	if len(e.Code) == 0 {
+		return e.Description()
+	}
+	return string(e.Code) + ": " + e.Description()
+}
+
+// This function will return the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// This function will report whether the target has the same error code. This is used by errors.Is.
+func (e *Error) Is(target error) bool {
+	targetError, ok := target.(*Error)
+	return ok && len(targetError.Code) > 0 && targetError.Code == e.Code
+}
+
+// This function will marshal the error to JSON. The underlying error is included in the message.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Code    ErrorCode `json:"code,omitempty"`
+		Op      string    `json:"op"`
+		Message string    `json:"message"`
+	}{e.Code, e.Op, e.Description()})
+}
+
+// Description will return the description of the error without the error code.
+func (e *Error) Description() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	cause := e.Err.Error()
+	if updateError, ok := e.Err.(*Error); ok {
+		cause = updateError.Description()
+	}
+	if len(e.Message) == 0 {
+		return cause
+	}
+	return e.Message + " " + cause
+}
+
+// GetErrorCode will return the error code of the given error. An empty code is returned if the error does not have a
+// code.
+func GetErrorCode(err error) ErrorCode {
+	if updateError, ok := err.(*Error); ok {
+		return updateError.Code
+	}
+	return ""
+}
+
+// Explain will return the explanation of the given error code. Codes are not case sensitive.
+func Explain(code string) (Explanation, bool) {
+	explanation, found := explanations[ErrorCode(strings.ToUpper(strings.TrimSpace(code)))]
+	return explanation, found
+}
+
+// GetErrorCodes will return all known error codes in ascending order.
+func GetErrorCodes() []ErrorCode {
+	codes := make([]ErrorCode, 0, len(explanations))
+	for code := range explanations {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// This function will create a new error with the given operation and message. If the given error is already an
+// *Error, it is returned without wrapping. Otherwise the code of the given error is kept.
+func newError(op string, err error, message string) error {
+	if updateError, ok := err.(*Error); ok && len(message) == 0 {
+		return updateError
+	}
+	return &Error{
+		Op: op,
+		Code: GetErrorCode(err),
+		Message: message,
+		Err: err,
+	}
+}
+
+// This function will create a new error which belongs to the failure class of the given code.
+func newErrorWithCode(op string, code ErrorCode, err error, message string) error {
+	return &Error{
+		Op: op,
+		Code: code,
+		Message: message,
+		Err: err,
+	}
+}
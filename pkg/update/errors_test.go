@@ -0,0 +1,43 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	err := newError(opCreate, newErrorWithCode(opValidate, CodeUnknownFile, nil, "Unknown file found: 'a.txt'."),
+		"Error occurred while validating the update.")
+	if !errors.Is(err, ErrUnknownFile) {
+		t.Errorf("Test failed. Expected '%v' to be %s", err, CodeUnknownFile)
+	}
+	if errors.Is(err, ErrBadFilename) {
+		t.Errorf("Test failed. Expected '%v' not to be %s", err, CodeBadFilename)
+	}
+	if GetErrorCode(err) != CodeUnknownFile {
+		t.Errorf("Test failed, expected: %s, actual: %s", CodeUnknownFile, GetErrorCode(err))
+	}
+	// The code should appear only once in the message
+	if strings.Count(err.Error(), string(CodeUnknownFile)) != 1 {
+		t.Errorf("Test failed. Unexpected message: %s", err.Error())
+	}
+}
+
+func TestExplain(t *testing.T) {
+	for _, code := range GetErrorCodes() {
+		explanation, found := Explain(strings.ToLower(string(code)))
+		if !found {
+			t.Errorf("Test failed. Explanation not found for %s", code)
+			continue
+		}
+		if len(explanation.Title) == 0 || len(explanation.Cause) == 0 || len(explanation.Fix) == 0 {
+			t.Errorf("Test failed. Explanation of %s is incomplete", code)
+		}
+	}
+	if _, found := Explain("UC-VAL-999"); found {
+		t.Error("Test failed. Expected no explanation for an unknown code")
+	}
+}
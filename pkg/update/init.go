@@ -0,0 +1,253 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// struct which is used to pass the values required to initialize an update directory
+type InitOptions struct {
+	// Directory where the update-descriptor.yaml should be created
+	Directory        string
+	// Whether the directory should be created if it does not exist
+	CreateDirectory  bool
+	// Platform versions and their platform names used to fill the platform_name field
+	PlatformVersions map[string]string
+	// Function which is called to change the values of the update descriptor before saving it. Values read from the
+	// README.txt are set before calling this function.
+	Customize        func(updateDescriptor *util.UpdateDescriptor) error
+	// Whether the next update number of the platform version should be allocated and set in the update descriptor
+	NextNumber       bool
+	// Values used to allocate the next update number. Platform version is taken from the update descriptor.
+	Allocation       AllocateOptions
+}
+
+// Init will create the update-descriptor.yaml in the given directory. Values are read from the README.txt in the old
+// patch format if it is available. Otherwise placeholders are added which should be edited manually.
+func Init(opts InitOptions) (*Result, error) {
+	logger.Debug("Initializing started.")
+	result := &Result{}
+
+	// Check whether the provided directory exists
+	exists, err := util.IsDirectoryExists(opts.Directory)
+	if err != nil {
+		return nil, newError(opInit, err, fmt.Sprintf("Error occurred while checking '%s'", opts.Directory))
+	}
+	logger.Debug(fmt.Sprintf("'%s' directory exists: %v", opts.Directory, exists))
+	if !exists {
+		if !opts.CreateDirectory {
+			return nil, newError(opInit, nil, "Directory creation skipped. Please enter a valid directory.")
+		}
+		err := util.CreateDirectory(opts.Directory)
+		if err != nil {
+			return nil, newError(opInit, err, "")
+		}
+		result.addMessage(fmt.Sprintf("'%s' directory does not exist. Creating '%s' directory.", opts.Directory, opts.Directory))
+		logger.Debug(fmt.Sprintf("'%s' directory created.", opts.Directory))
+	}
+
+	// Create a new update descriptor struct
+	updateDescriptor := util.UpdateDescriptor{}
+
+	// Process README.txt and parse values
+	processReadMe(opts.Directory, &updateDescriptor, opts.PlatformVersions)
+
+	// Let the caller change the values. Values parsed from the README.txt will be used as the default values.
+	if opts.Customize != nil {
+		err = opts.Customize(&updateDescriptor)
+		if err != nil {
+			return nil, newError(opInit, err, "")
+		}
+	}
+
+	// Allocate the next update number after the platform version is known
+	if opts.NextNumber {
+		allocateOptions := opts.Allocation
+		allocateOptions.PlatformVersion = updateDescriptor.Platform_version
+		updateNumber, err := AllocateUpdateNumber(allocateOptions)
+		if err != nil {
+			return nil, newError(opInit, err, "Error occurred while allocating the update number.")
+		}
+		updateDescriptor.Update_number = updateNumber
+		result.addMessage(fmt.Sprintf("Update number %s reserved for platform version %s.", updateNumber,
+			updateDescriptor.Platform_version))
+	}
+
+	// Marshall the update descriptor struct
+	data, err := util.MarshalUpdateDescriptor(&updateDescriptor)
+	if err != nil {
+		return nil, newError(opInit, err, "")
+	}
+	logger.Debug(fmt.Sprintf("update-descriptor:\n%s", string(data)))
+
+	// Construct the update descriptor file path
+	updateDescriptorFile := filepath.Join(opts.Directory, constant.UPDATE_DESCRIPTOR_FILE)
+	logger.Debug(fmt.Sprintf("updateDescriptorFile: %v", updateDescriptorFile))
+
+	// Save the update descriptor
+	err = ioutil.WriteFile(updateDescriptorFile, data, 0600)
+	if err != nil {
+		return nil, newError(opInit, err, "")
+	}
+	result.OutputPath = updateDescriptorFile
+	return result, nil
+}
+
+//This function will set default valued to the update-descriptor.yaml.
+func setUpdateDescriptorDefaultValues(updateDescriptor *util.UpdateDescriptor) {
+	logger.Debug("Setting default values:")
+	updateDescriptor.Update_number = constant.UPDATE_NO_DEFAULT
+	updateDescriptor.Platform_name = constant.PLATFORM_NAME_DEFAULT
+	updateDescriptor.Platform_version = constant.PLATFORM_VERSION_DEFAULT
+	updateDescriptor.Applies_to = constant.APPLIES_TO_DEFAULT
+	updateDescriptor.Description = constant.DESCRIPTION_DEFAULT
+	bugFixes := map[string]string{
+		constant.JIRA_KEY_DEFAULT: constant.JIRA_SUMMARY_DEFAULT,
+	}
+	updateDescriptor.Bug_fixes = bugFixes
+	logger.Debug(fmt.Sprintf("bug_fixes: %v", bugFixes))
+}
+
+//This function will process the readme file and extract details to populate update-descriptor.yaml. If some data cannot
+// be extracted, it will add default value and continue.
+func processReadMe(directory string, updateDescriptor *util.UpdateDescriptor, platformVersions map[string]string) {
+	logger.Debug("Processing README started")
+	// Construct the README.txt path
+	readMePath := path.Join(directory, constant.README_FILE)
+	logger.Debug(fmt.Sprintf("README Path: %v", readMePath))
+	// Check whether the README.txt file exists
+	_, err := os.Stat(readMePath)
+	if err != nil {
+		// If the file does not exist or any other error occur, return without printing warning messages
+		logger.Debug(fmt.Sprintf("%s not found", readMePath))
+		setUpdateDescriptorDefaultValues(updateDescriptor)
+		return
+	}
+	// Read the README.txt file
+	data, err := ioutil.ReadFile(readMePath)
+	if err != nil {
+		// If any error occurs, return without printing warning messages
+		logger.Debug(fmt.Sprintf("Error occurred and processing README: %v", err))
+		setUpdateDescriptorDefaultValues(updateDescriptor)
+		return
+	}
+
+	logger.Debug("README.txt found")
+
+	// Convert the byte array to a string
+	stringData := string(data)
+	// Compile the regex
+	regex, err := regexp.Compile(constant.PATCH_ID_REGEX)
+	if err == nil {
+		result := regex.FindStringSubmatch(stringData)
+		logger.Trace(fmt.Sprintf("PATCH_ID_REGEX result: %v", result))
+		// Since the regex has 2 capturing groups, the result size will be 3 (because there is the full match)
+		// If not match found, the size will be 0. We check whether the result size is not 0 to make sure both
+		// capturing groups are identified.
+		if len(result) != 0 {
+			// Extract details
+			updateDescriptor.Update_number = result[2]
+			updateDescriptor.Platform_version = result[1]
+			logger.Trace(fmt.Sprintf("Platform Map: %v", platformVersions))
+			// Get the platform details from the map
+			platformName, found := platformVersions[result[1]]
+			if found {
+				logger.Debug("PlatformName found in configs")
+				updateDescriptor.Platform_name = platformName
+			} else {
+				//If the platform name is not found, set default
+				logger.Debug("No matching platform name found for:", result[1])
+				updateDescriptor.Platform_name = constant.PLATFORM_NAME_DEFAULT
+			}
+		} else {
+			logger.Debug("PATCH_ID_REGEX results incorrect:", result)
+		}
+	} else {
+		//If error occurred, set default values
+		logger.Debug(fmt.Sprintf("Error occurred while processing PATCH_ID_REGEX: %v", err))
+		updateDescriptor.Update_number = constant.UPDATE_NO_DEFAULT
+		updateDescriptor.Platform_name = constant.PLATFORM_NAME_DEFAULT
+		updateDescriptor.Platform_version = constant.PLATFORM_VERSION_DEFAULT
+	}
+
+	// Compile the regex
+	regex, err = regexp.Compile(constant.APPLIES_TO_REGEX)
+	if err == nil {
+		result := regex.FindStringSubmatch(stringData)
+		logger.Trace(fmt.Sprintf("APPLIES_TO_REGEX result: %v", result))
+		// In the README, Associated Jiras section might not appear. If it does appear, result size will be 2.
+		// If it does not appear, result size will be 3.
+		if len(result) == 2 {
+			// If the result size is 2, we know that 1st index contains the 1st capturing group.
+			updateDescriptor.Applies_to = util.ProcessString(result[1], ", ", true)
+		} else if len(result) == 3 {
+			// If the result size is 3, 1st or 2nd string might contain the match. So we concat them
+			// together and trim the spaces. If one field has an empty string, it will be trimmed.
+			updateDescriptor.Applies_to = util.ProcessString(strings.TrimSpace(result[1] + result[2]), ", ", true)
+		} else {
+			logger.Debug("No matching results found for APPLIES_TO_REGEX:", result)
+		}
+	} else {
+		//If error occurred, set default value
+		logger.Debug(fmt.Sprintf("Error occurred while processing APPLIES_TO_REGEX: %v", err))
+		updateDescriptor.Applies_to = constant.APPLIES_TO_DEFAULT
+	}
+
+	// Compile the regex
+	regex, err = regexp.Compile(constant.ASSOCIATED_JIRAS_REGEX)
+	if err == nil {
+		// Get all matches because there might be multiple Jiras.
+		allResult := regex.FindAllStringSubmatch(stringData, -1)
+		logger.Trace(fmt.Sprintf("APPLIES_TO_REGEX result: %v", allResult))
+		updateDescriptor.Bug_fixes = make(map[string]string)
+		// If no Jiras found, set 'N/A: N/A' as the value
+		if len(allResult) == 0 {
+			logger.Debug("No matching results found for ASSOCIATED_JIRAS_REGEX. Setting default values.")
+			updateDescriptor.Bug_fixes[constant.JIRA_NA] = constant.JIRA_NA
+		} else {
+			// If Jiras found, get summary for all Jiras
+			logger.Debug("Matching results found for ASSOCIATED_JIRAS_REGEX")
+			for i, match := range allResult {
+				// Regex has a one capturing group. So the jira ID will be in the 1st index.
+				logger.Debug(fmt.Sprintf("%d: %s", i, match[1]))
+				logger.Debug(fmt.Sprintf("ASSOCIATED_JIRAS_REGEX results is correct: %v", match))
+				updateDescriptor.Bug_fixes[match[1]] = util.GetJiraSummary(match[1])
+			}
+		}
+	} else {
+		//If error occurred, set default values
+		logger.Debug(fmt.Sprintf("Error occurred while processing ASSOCIATED_JIRAS_REGEX: %v", err))
+		logger.Debug("Setting defailt values to bug_fixes")
+		updateDescriptor.Bug_fixes = make(map[string]string)
+		updateDescriptor.Bug_fixes[constant.JIRA_KEY_DEFAULT] = constant.JIRA_SUMMARY_DEFAULT
+	}
+
+	// Compile the regex
+	regex, err = regexp.Compile(constant.DESCRIPTION_REGEX)
+	if err == nil {
+		// Get the match
+		result := regex.FindStringSubmatch(stringData)
+		logger.Trace(fmt.Sprintf("DESCRIPTION_REGEX result: %v", result))
+		// If there is a match, process it and store it
+		if len(result) != 0 {
+			updateDescriptor.Description = util.ProcessString(result[1], "\n", false)
+		} else {
+			logger.Debug(fmt.Sprintf("No matching results found for DESCRIPTION_REGEX: %v", result))
+		}
+	} else {
+		//If error occurred, set default values
+		logger.Debug(fmt.Sprintf("Error occurred while processing DESCRIPTION_REGEX: %v", err))
+		updateDescriptor.Description = constant.DESCRIPTION_DEFAULT
+	}
+	logger.Debug("Processing README finished")
+}
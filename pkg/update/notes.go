@@ -0,0 +1,362 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Formats of the release notes
+const (
+	NotesFormatMarkdown = "md"
+	NotesFormatHtml = "html"
+	NotesFormatText = "text"
+)
+
+// struct which is used to pass the values required to generate release notes
+type NotesOptions struct {
+	// Locations of the update zips
+	UpdateFilePaths []string
+	// One of NotesFormatMarkdown, NotesFormatHtml or NotesFormatText. Markdown is used if this is empty.
+	Format          string
+	// Location of a Go template file which is used instead of the built-in template of the format. Templates of the
+	// html format are parsed using html/template so that values are escaped.
+	TemplatePath    string
+}
+
+// NotesData is the value passed to the release notes template.
+type NotesData struct {
+	// Updates grouped by the platform version in ascending order
+	Platforms []PlatformNotes
+}
+
+// PlatformNotes contains the updates of a single platform version.
+type PlatformNotes struct {
+	PlatformVersion string
+	PlatformName    string
+	// Updates in ascending order of the update number
+	Updates         []UpdateNotes
+}
+
+// UpdateNotes contains the details of a single update used in the release notes.
+type UpdateNotes struct {
+	UpdateName      string
+	UpdateNumber    string
+	PlatformVersion string
+	PlatformName    string
+	AppliesTo       []string
+	// Bug fixes in ascending order of the JIRA key
+	BugFixes        []BugFix
+	Description     string
+	// Content of the instructions.txt. This is empty if the update does not have instructions.
+	Instructions    string
+	// Content of the instructions.yaml. This is nil if the update does not have structured instructions.
+	StructuredInstructions *util.Instructions
+	FileChanges     FileChanges
+}
+
+// BugFix is a JIRA key and its summary.
+type BugFix struct {
+	Key     string
+	Summary string
+}
+
+// Built-in templates of the formats
+var notesTemplates = map[string]string{
+	NotesFormatMarkdown: `# Release Notes
+{{range .Platforms}}
+## Platform {{.PlatformVersion}}{{if .PlatformName}} ({{.PlatformName}}){{end}}
+{{range .Updates}}
+### {{.UpdateName}}
+
+**Applies to:** {{join .AppliesTo ", "}}
+
+{{.Description}}
+
+**Bug fixes:**
+{{range .BugFixes}}
+- {{.Key}}: {{.Summary}}{{end}}
+{{if .Instructions}}
+**Instructions:**
+
+{{.Instructions}}
+{{end}}{{with .StructuredInstructions}}{{if .ConfigChanges}}
+**Configuration changes:**
+{{range .ConfigChanges}}
+- ` + "`{{.File}}`" + `: {{.Description}}{{end}}
+{{end}}{{if .DatabaseScripts}}
+**Database scripts:**
+{{range .DatabaseScripts}}
+- ` + "`{{.File}}`" + `{{if .Database}} ({{.Database}}){{end}}{{if .Description}}: {{.Description}}{{end}}{{end}}
+{{end}}{{if .RestartRequired}}
+**Restart required:** Yes
+{{end}}{{if .ClusterNotes}}
+**Cluster notes:**
+
+{{.ClusterNotes}}
+{{end}}{{end}}{{with .FileChanges}}{{if .AddedFiles}}
+**Added files:**
+{{range .AddedFiles}}
+- ` + "`{{.}}`" + `{{end}}
+{{end}}{{if .RemovedFiles}}
+**Removed files:**
+{{range .RemovedFiles}}
+- ` + "`{{.}}`" + `{{end}}
+{{end}}{{if .ModifiedFiles}}
+**Modified files:**
+{{range .ModifiedFiles}}
+- ` + "`{{.}}`" + `{{end}}
+{{end}}{{end}}{{end}}{{end}}`,
+
+	NotesFormatHtml: `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Release Notes</title></head>
+<body>
+<h1>Release Notes</h1>
+{{range .Platforms}}<h2>Platform {{.PlatformVersion}}{{if .PlatformName}} ({{.PlatformName}}){{end}}</h2>
+{{range .Updates}}<h3>{{.UpdateName}}</h3>
+<p><strong>Applies to:</strong> {{join .AppliesTo ", "}}</p>
+<pre>{{.Description}}</pre>
+<p><strong>Bug fixes:</strong></p>
+<ul>
+{{range .BugFixes}}<li>{{.Key}}: {{.Summary}}</li>
+{{end}}</ul>
+{{if .Instructions}}<p><strong>Instructions:</strong></p>
+<pre>{{.Instructions}}</pre>
+{{end}}{{with .StructuredInstructions}}{{if .ConfigChanges}}<p><strong>Configuration changes:</strong></p>
+<ul>
+{{range .ConfigChanges}}<li><code>{{.File}}</code>: {{.Description}}</li>
+{{end}}</ul>
+{{end}}{{if .DatabaseScripts}}<p><strong>Database scripts:</strong></p>
+<ul>
+{{range .DatabaseScripts}}<li><code>{{.File}}</code>{{if .Database}} ({{.Database}}){{end}}{{if .Description}}: {{.Description}}{{end}}</li>
+{{end}}</ul>
+{{end}}{{if .RestartRequired}}<p><strong>Restart required:</strong> Yes</p>
+{{end}}{{if .ClusterNotes}}<p><strong>Cluster notes:</strong></p>
+<pre>{{.ClusterNotes}}</pre>
+{{end}}{{end}}{{with .FileChanges}}{{if .AddedFiles}}<p><strong>Added files:</strong></p>
+<ul>
+{{range .AddedFiles}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}{{if .RemovedFiles}}<p><strong>Removed files:</strong></p>
+<ul>
+{{range .RemovedFiles}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}{{if .ModifiedFiles}}<p><strong>Modified files:</strong></p>
+<ul>
+{{range .ModifiedFiles}}<li><code>{{.}}</code></li>
+{{end}}</ul>
+{{end}}{{end}}{{end}}{{end}}</body>
+</html>
+`,
+
+	NotesFormatText: `RELEASE NOTES
+{{range .Platforms}}
+PLATFORM {{.PlatformVersion}}{{if .PlatformName}} ({{.PlatformName}}){{end}}
+{{range .Updates}}
+{{.UpdateName}}
+Applies to: {{join .AppliesTo ", "}}
+
+{{.Description}}
+
+Bug fixes:
+{{range .BugFixes}}  * {{.Key}}: {{.Summary}}
+{{end}}{{if .Instructions}}
+Instructions:
+{{.Instructions}}
+{{end}}{{with .StructuredInstructions}}{{if .ConfigChanges}}
+Configuration changes:
+{{range .ConfigChanges}}  * {{.File}}: {{.Description}}
+{{end}}{{end}}{{if .DatabaseScripts}}
+Database scripts:
+{{range .DatabaseScripts}}  * {{.File}}{{if .Database}} ({{.Database}}){{end}}{{if .Description}}: {{.Description}}{{end}}
+{{end}}{{end}}{{if .RestartRequired}}
+Restart required: Yes
+{{end}}{{if .ClusterNotes}}
+Cluster notes:
+{{.ClusterNotes}}
+{{end}}{{end}}{{with .FileChanges}}{{if .AddedFiles}}
+Added files:
+{{range .AddedFiles}}  * {{.}}
+{{end}}{{end}}{{if .RemovedFiles}}
+Removed files:
+{{range .RemovedFiles}}  * {{.}}
+{{end}}{{end}}{{if .ModifiedFiles}}
+Modified files:
+{{range .ModifiedFiles}}  * {{.}}
+{{end}}{{end}}{{end}}{{end}}{{end}}`,
+}
+
+// Functions which can be used in the release notes templates
+var notesTemplateFuncs = map[string]interface{}{
+	"join": strings.Join,
+	"trim": strings.TrimSpace,
+	"upper": strings.ToUpper,
+}
+
+// GetNotesFormats will return the supported formats of the release notes.
+func GetNotesFormats() []string {
+	return []string{NotesFormatMarkdown, NotesFormatHtml, NotesFormatText}
+}
+
+// GenerateNotes will read the given update zips and write the release notes to the given writer. Updates are grouped
+// by the platform version. The layout is taken from the template file if it is given. Otherwise the built-in template
+// of the format is used.
+func GenerateNotes(opts NotesOptions, writer io.Writer) error {
+	format := opts.Format
+	if len(format) == 0 {
+		format = NotesFormatMarkdown
+	}
+	templateText, found := notesTemplates[format]
+	if !found {
+		return newError(opNotes, nil, fmt.Sprintf("Format '%s' is not supported. Supported formats are %s.", format,
+			strings.Join(GetNotesFormats(), ", ")))
+	}
+	if len(opts.TemplatePath) > 0 {
+		data, err := ioutil.ReadFile(opts.TemplatePath)
+		if err != nil {
+			return newError(opNotes, err, fmt.Sprintf("Error occurred while reading the template '%s'.", opts.TemplatePath))
+		}
+		templateText = string(data)
+	}
+
+	notesData, err := readNotesData(opts.UpdateFilePaths)
+	if err != nil {
+		return err
+	}
+
+	// html/template is used for html so that the values in the update-descriptor.yaml are escaped
+	if format == NotesFormatHtml {
+		notesTemplate, err := htmltemplate.New("notes").Funcs(notesTemplateFuncs).Parse(templateText)
+		if err != nil {
+			return newError(opNotes, err, "Error occurred while parsing the template.")
+		}
+		err = notesTemplate.Execute(writer, notesData)
+		if err != nil {
+			return newError(opNotes, err, "Error occurred while rendering the release notes.")
+		}
+		return nil
+	}
+	notesTemplate, err := template.New("notes").Funcs(notesTemplateFuncs).Parse(templateText)
+	if err != nil {
+		return newError(opNotes, err, "Error occurred while parsing the template.")
+	}
+	err = notesTemplate.Execute(writer, notesData)
+	if err != nil {
+		return newError(opNotes, err, "Error occurred while rendering the release notes.")
+	}
+	return nil
+}
+
+// This function will read the given update zips and group them by the platform version.
+func readNotesData(updateFilePaths []string) (*NotesData, error) {
+	platformsMap := make(map[string]*PlatformNotes)
+	for _, updateFilePath := range updateFilePaths {
+		updateNotes, err := readUpdateNotes(updateFilePath)
+		if err != nil {
+			return nil, err
+		}
+		platformNotes, found := platformsMap[updateNotes.PlatformVersion]
+		if !found {
+			platformNotes = &PlatformNotes{
+				PlatformVersion: updateNotes.PlatformVersion,
+				PlatformName: updateNotes.PlatformName,
+			}
+			platformsMap[updateNotes.PlatformVersion] = platformNotes
+		}
+		platformNotes.Updates = append(platformNotes.Updates, *updateNotes)
+	}
+
+	notesData := &NotesData{
+		Platforms: make([]PlatformNotes, 0),
+	}
+	for _, platformNotes := range platformsMap {
+		sort.Slice(platformNotes.Updates, func(i, j int) bool {
+			return platformNotes.Updates[i].UpdateNumber < platformNotes.Updates[j].UpdateNumber
+		})
+		notesData.Platforms = append(notesData.Platforms, *platformNotes)
+	}
+	sort.Slice(notesData.Platforms, func(i, j int) bool {
+		return compareVersions(notesData.Platforms[i].PlatformVersion, notesData.Platforms[j].PlatformVersion) < 0
+	})
+	return notesData, nil
+}
+
+// This function will read the details of the given update zip which are used in the release notes.
+func readUpdateNotes(updateFilePath string) (*UpdateNotes, error) {
+	updateDescriptor, err := util.ReadUpdateDescriptorFromZip(updateFilePath)
+	if err != nil {
+		return nil, newErrorWithCode(opNotes, CodeDescriptorInvalid, err, fmt.Sprintf("Error occurred while reading '%s'.", updateFilePath))
+	}
+	instructions, _, err := util.ReadFileFromUpdateZip(updateFilePath, constant.INSTRUCTIONS_FILE)
+	if err != nil {
+		return nil, newError(opNotes, err, fmt.Sprintf("Error occurred while reading '%s'.", updateFilePath))
+	}
+	var structuredInstructions *util.Instructions
+	data, found, err := util.ReadFileFromUpdateZip(updateFilePath, constant.INSTRUCTIONS_YAML_FILE)
+	if err != nil {
+		return nil, newError(opNotes, err, fmt.Sprintf("Error occurred while reading '%s'.", updateFilePath))
+	}
+	if found {
+		structuredInstructions, err = util.UnmarshalInstructions(data)
+		if err != nil {
+			return nil, newErrorWithCode(opNotes, CodeInstructionsInvalid, err, fmt.Sprintf("'%s' of '%s' is invalid.",
+				constant.INSTRUCTIONS_YAML_FILE, updateFilePath))
+		}
+	}
+
+	bugFixes := make([]BugFix, 0)
+	for key, summary := range updateDescriptor.Bug_fixes {
+		bugFixes = append(bugFixes, BugFix{Key: key, Summary: summary})
+	}
+	sort.Slice(bugFixes, func(i, j int) bool {
+		return bugFixes[i].Key < bugFixes[j].Key
+	})
+	return &UpdateNotes{
+		UpdateName: getUpdateName(updateDescriptor, constant.UPDATE_NAME_PREFIX),
+		UpdateNumber: updateDescriptor.Update_number,
+		PlatformVersion: updateDescriptor.Platform_version,
+		PlatformName: updateDescriptor.Platform_name,
+		AppliesTo: util.GetProductsInAppliesTo(updateDescriptor.Applies_to),
+		BugFixes: bugFixes,
+		Description: strings.TrimSpace(updateDescriptor.Description),
+		Instructions: strings.TrimSpace(string(instructions)),
+		StructuredInstructions: structuredInstructions,
+		FileChanges: FileChanges{
+			AddedFiles: updateDescriptor.File_changes.Added_files,
+			RemovedFiles: updateDescriptor.File_changes.Removed_files,
+			ModifiedFiles: updateDescriptor.File_changes.Modified_files,
+		},
+	}, nil
+}
+
+// This function will compare the given versions numerically. Ex: 4.10.0 is greater than 4.4.0. It returns a negative
+// value if the first version is lower, a positive value if it is greater and 0 if they are equal.
+func compareVersions(first, second string) int {
+	firstParts := strings.Split(first, ".")
+	secondParts := strings.Split(second, ".")
+	for i := 0; i < len(firstParts) && i < len(secondParts); i++ {
+		firstNumber, firstErr := strconv.Atoi(firstParts[i])
+		secondNumber, secondErr := strconv.Atoi(secondParts[i])
+		if firstErr != nil || secondErr != nil {
+			if firstParts[i] != secondParts[i] {
+				return strings.Compare(firstParts[i], secondParts[i])
+			}
+			continue
+		}
+		if firstNumber != secondNumber {
+			return firstNumber - secondNumber
+		}
+	}
+	return len(firstParts) - len(secondParts)
+}
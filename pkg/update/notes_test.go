@@ -0,0 +1,98 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestGenerateNotes(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	updatePaths := []string{
+		filepath.Join(root, "WSO2-CARBON-UPDATE-4.4.0-0002.zip"),
+		filepath.Join(root, "WSO2-CARBON-UPDATE-4.10.0-0001.zip"),
+		filepath.Join(root, "WSO2-CARBON-UPDATE-4.4.0-0001.zip"),
+	}
+	writeUpdate(t, updatePaths[0], "WSO2-CARBON-UPDATE-4.4.0-0002", "0002", "wso2esb-4.9.0", "repository/components/plugins/bar.jar")
+	writeZip(t, updatePaths[1], map[string]string{
+		"WSO2-CARBON-UPDATE-4.10.0-0001/" + constant.UPDATE_DESCRIPTOR_FILE: `update_number: 0001
+platform_version: 4.10.0
+platform_name: perlis
+applies_to: wso2am-3.0.0
+bug_fixes:
+  CARBON-3: Fix <something>
+description: Fixes something else.
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files:
+  - repository/components/plugins/baz.jar
+`,
+		"WSO2-CARBON-UPDATE-4.10.0-0001/" + constant.INSTRUCTIONS_FILE: "Restart the server.",
+		"WSO2-CARBON-UPDATE-4.10.0-0001/" + constant.INSTRUCTIONS_YAML_FILE: `config_changes:
+- file: repository/conf/carbon.xml
+  description: Set the HostName.
+restart_required: true
+`,
+	})
+	writeUpdate(t, updatePaths[2], "WSO2-CARBON-UPDATE-4.4.0-0001", "0001", "wso2esb-4.9.0", "repository/components/plugins/foo.jar")
+
+	buffer := bytes.Buffer{}
+	err = GenerateNotes(NotesOptions{UpdateFilePaths: updatePaths}, &buffer)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	notes := buffer.String()
+	expectedOrder := []string{"## Platform 4.4.0 (wilkes)", "WSO2-CARBON-UPDATE-4.4.0-0001", "WSO2-CARBON-UPDATE-4.4.0-0002",
+		"## Platform 4.10.0 (perlis)", "WSO2-CARBON-UPDATE-4.10.0-0001", "Restart the server.",
+		"- `repository/conf/carbon.xml`: Set the HostName.", "**Restart required:** Yes"}
+	index := 0
+	for _, expected := range expectedOrder {
+		next := strings.Index(notes[index:], expected)
+		if next < 0 {
+			t.Fatalf("Test failed, expected: %s, actual: %s", expected, notes)
+		}
+		index += next
+	}
+
+	buffer.Reset()
+	err = GenerateNotes(NotesOptions{UpdateFilePaths: updatePaths, Format: NotesFormatHtml}, &buffer)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if !strings.Contains(buffer.String(), "Fix &lt;something&gt;") {
+		t.Errorf("Test failed, expected: %s, actual: %s", "Fix &lt;something&gt;", buffer.String())
+	}
+
+	templatePath := filepath.Join(root, "notes.tmpl")
+	err = ioutil.WriteFile(templatePath, []byte(`{{range .Platforms}}{{range .Updates}}{{.UpdateName}};{{end}}{{end}}`), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	buffer.Reset()
+	err = GenerateNotes(NotesOptions{UpdateFilePaths: updatePaths, TemplatePath: templatePath}, &buffer)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	expected := "WSO2-CARBON-UPDATE-4.4.0-0001;WSO2-CARBON-UPDATE-4.4.0-0002;WSO2-CARBON-UPDATE-4.10.0-0001;"
+	if buffer.String() != expected {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, buffer.String())
+	}
+
+	err = GenerateNotes(NotesOptions{UpdateFilePaths: updatePaths, Format: "pdf"}, &buffer)
+	if err == nil {
+		t.Errorf("Test failed. Expected an error for an unsupported format.")
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// Time to wait for the lock of the registry before giving up
+const registryLockTimeout = 30 * time.Second
+
+// Lock files older than this are considered as left by crashed runs
+const registryLockStaleAfter = 10 * time.Minute
+
+// Registry is the JSON store of the released and reserved update numbers. It is shared between the developers so that
+// two developers do not use the same update number. Metadata and file changes of released updates are stored as well
+// so that the registry can be searched.
+type Registry struct {
+	Updates []RegistryEntry `json:"updates"`
+}
+
+// struct which is used to store an update number and the details of the update in the registry
+type RegistryEntry struct {
+	PlatformVersion string            `json:"platform_version"`
+	UpdateNumber    string            `json:"update_number"`
+	// One of constant.REGISTRY_STATUS_RESERVED or constant.REGISTRY_STATUS_RELEASED
+	Status          string            `json:"status"`
+	ReservedBy      string            `json:"reserved_by,omitempty"`
+	// Time the update number was reserved in RFC 3339 format
+	ReservedAt      string            `json:"reserved_at,omitempty"`
+	// Following fields are set when a released update is added to the registry
+	UpdateName      string            `json:"update_name,omitempty"`
+	PlatformName    string            `json:"platform_name,omitempty"`
+	AppliesTo       string            `json:"applies_to,omitempty"`
+	BugFixes        map[string]string `json:"bug_fixes,omitempty"`
+	Description     string            `json:"description,omitempty"`
+	FileChanges     *FileChanges      `json:"file_changes,omitempty"`
+	// Time the update was added to the registry in RFC 3339 format
+	AddedAt         string            `json:"added_at,omitempty"`
+}
+
+// struct which is used to pass the location of the registry
+type RegistryOptions struct {
+	// Location of the registry file. If this is empty, the registry in the KnownUpdatesDirectory is used.
+	RegistryPath          string
+	// Directory which contains the released update zips
+	KnownUpdatesDirectory string
+}
+
+// struct which is used to search the registry. Only the entries which match all given values are returned.
+type RegistryQuery struct {
+	// JIRA key in the bug_fixes field. Ex: CARBON-15395
+	JiraKey         string
+	// Path of an added, removed or modified file relative to CARBON_HOME. Entries which changed a file with the given
+	// path as the suffix are returned as well. Ex: repository/components/plugins/foo.jar, foo.jar
+	File            string
+	PlatformVersion string
+}
+
+// Columns of the CSV export of the registry
+var registryCsvHeader = []string{"platform_version", "update_number", "update_name", "status", "platform_name",
+	"applies_to", "bug_fixes", "added_files", "removed_files", "modified_files", "reserved_by", "reserved_at",
+	"added_at"}
+
+// AddToRegistry will add the metadata and the file changes of the given update zip to the registry. If the update
+// number is already in the registry, for example because it was reserved using AllocateUpdateNumber, the entry is
+// replaced and its status is changed to released.
+func AddToRegistry(opts RegistryOptions, updateZipPath string) (*Result, error) {
+	result := &Result{}
+	updateDescriptor, err := util.ReadUpdateDescriptorFromZip(updateZipPath)
+	if err != nil {
+		return nil, newErrorWithCode(opRegistry, CodeDescriptorInvalid, err, fmt.Sprintf("Error occurred while reading '%s'.", updateZipPath))
+	}
+	if err := util.ValidateUpdateDescriptor(updateDescriptor); err != nil {
+		return nil, newErrorWithCode(opRegistry, CodeDescriptorInvalid, err, fmt.Sprintf("'%s' of '%s' is not valid.",
+			constant.UPDATE_DESCRIPTOR_FILE, updateZipPath))
+	}
+	result.UpdateName = getUpdateName(updateDescriptor, constant.UPDATE_NAME_PREFIX)
+	result.UpdateNumber = updateDescriptor.Update_number
+	result.PlatformVersion = updateDescriptor.Platform_version
+	result.setFileChanges(updateDescriptor)
+
+	registryPath, err := getConfiguredRegistryPath(opRegistry, opts.RegistryPath, opts.KnownUpdatesDirectory)
+	if err != nil {
+		return nil, err
+	}
+	releaseLock, err := lockRegistry(opRegistry, registryPath)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseLock()
+
+	registry, err := loadRegistry(registryPath)
+	if err != nil {
+		return nil, newError(opRegistry, err, fmt.Sprintf("Error occurred while reading the registry '%s'.", registryPath))
+	}
+	fileChanges := result.FileChanges
+	entry := RegistryEntry{
+		PlatformVersion: updateDescriptor.Platform_version,
+		UpdateNumber: updateDescriptor.Update_number,
+		Status: constant.REGISTRY_STATUS_RELEASED,
+		UpdateName: result.UpdateName,
+		PlatformName: updateDescriptor.Platform_name,
+		AppliesTo: updateDescriptor.Applies_to,
+		BugFixes: updateDescriptor.Bug_fixes,
+		Description: strings.TrimSpace(updateDescriptor.Description),
+		FileChanges: &fileChanges,
+		AddedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	replaced := false
+	for i, existingEntry := range registry.Updates {
+		if existingEntry.PlatformVersion != entry.PlatformVersion || existingEntry.UpdateNumber != entry.UpdateNumber {
+			continue
+		}
+		// Keep the details of the reservation
+		entry.ReservedBy = existingEntry.ReservedBy
+		entry.ReservedAt = existingEntry.ReservedAt
+		registry.Updates[i] = entry
+		replaced = true
+		break
+	}
+	if replaced {
+		result.addMessage(fmt.Sprintf("'%s' updated in the registry.", result.UpdateName))
+	} else {
+		registry.Updates = append(registry.Updates, entry)
+		result.addMessage(fmt.Sprintf("'%s' added to the registry.", result.UpdateName))
+	}
+	err = saveRegistry(registryPath, registry)
+	if err != nil {
+		return nil, newError(opRegistry, err, fmt.Sprintf("Error occurred while saving the registry '%s'.", registryPath))
+	}
+	result.OutputPath = registryPath
+	return result, nil
+}
+
+// SearchRegistry will return the registry entries which match the given query sorted by the platform version and the
+// update number. All entries are returned if the query is empty.
+func SearchRegistry(opts RegistryOptions, query RegistryQuery) ([]RegistryEntry, error) {
+	registry, err := readRegistry(opts)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]RegistryEntry, 0)
+	for _, entry := range registry.Updates {
+		if entry.matches(query) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// ExportRegistry will write all entries of the registry to the given writer in CSV format. Lists such as the bug
+// fixes and the file changes are joined using ';'.
+func ExportRegistry(opts RegistryOptions, writer io.Writer) error {
+	registry, err := readRegistry(opts)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(registryCsvHeader); err != nil {
+		return newError(opRegistry, err, "Error occurred while exporting the registry.")
+	}
+	for _, entry := range registry.Updates {
+		fileChanges := FileChanges{}
+		if entry.FileChanges != nil {
+			fileChanges = *entry.FileChanges
+		}
+		err := csvWriter.Write([]string{entry.PlatformVersion, entry.UpdateNumber, entry.UpdateName, entry.Status,
+			entry.PlatformName, entry.AppliesTo, strings.Join(entry.GetJiraKeys(), ";"),
+			strings.Join(fileChanges.AddedFiles, ";"), strings.Join(fileChanges.RemovedFiles, ";"),
+			strings.Join(fileChanges.ModifiedFiles, ";"), entry.ReservedBy, entry.ReservedAt, entry.AddedAt})
+		if err != nil {
+			return newError(opRegistry, err, "Error occurred while exporting the registry.")
+		}
+	}
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return newError(opRegistry, err, "Error occurred while exporting the registry.")
+	}
+	return nil
+}
+
+// GetJiraKeys will return the sorted JIRA keys of the bug fixes of the entry.
+func (entry RegistryEntry) GetJiraKeys() []string {
+	keys := make([]string, 0)
+	for key := range entry.BugFixes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// This function will check whether the entry matches all values of the given query.
+func (entry RegistryEntry) matches(query RegistryQuery) bool {
+	if len(query.PlatformVersion) > 0 && entry.PlatformVersion != query.PlatformVersion {
+		return false
+	}
+	if len(query.JiraKey) > 0 {
+		if _, found := entry.BugFixes[query.JiraKey]; !found {
+			return false
+		}
+	}
+	if len(query.File) > 0 {
+		if entry.FileChanges == nil {
+			return false
+		}
+		file := strings.TrimPrefix(filepath.ToSlash(query.File), "/")
+		found := false
+		for _, files := range [][]string{entry.FileChanges.AddedFiles, entry.FileChanges.RemovedFiles,
+			entry.FileChanges.ModifiedFiles} {
+			for _, changedFile := range files {
+				if changedFile == file || strings.HasSuffix(changedFile, "/" + file) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// This function will read the configured registry and sort its entries by the platform version and the update number.
+func readRegistry(opts RegistryOptions) (*Registry, error) {
+	registryPath, err := getConfiguredRegistryPath(opRegistry, opts.RegistryPath, opts.KnownUpdatesDirectory)
+	if err != nil {
+		return nil, err
+	}
+	registry, err := loadRegistry(registryPath)
+	if err != nil {
+		return nil, newError(opRegistry, err, fmt.Sprintf("Error occurred while reading the registry '%s'.", registryPath))
+	}
+	sort.SliceStable(registry.Updates, func(i, j int) bool {
+		comparison := compareVersions(registry.Updates[i].PlatformVersion, registry.Updates[j].PlatformVersion)
+		if comparison != 0 {
+			return comparison < 0
+		}
+		return registry.Updates[i].UpdateNumber < registry.Updates[j].UpdateNumber
+	})
+	return registry, nil
+}
+
+// This function will return the location of the registry. If the location is not given, the registry in the given
+// directory of known updates is used. An error is returned if neither of them is given.
+func getConfiguredRegistryPath(op, registryPath, knownUpdatesDirectory string) (string, error) {
+	if len(registryPath) > 0 {
+		return registryPath, nil
+	}
+	if len(knownUpdatesDirectory) > 0 {
+		return filepath.Join(knownUpdatesDirectory, constant.REGISTRY_FILE), nil
+	}
+	return "", newError(op, nil, "Directory of known updates or the registry is not configured. Set "+
+		"'known_updates_directory' or 'update_registry' in the config.yaml.")
+}
+
+// This function will lock the registry at the given location using a lock file next to it. The returned function
+// releases the lock.
+func lockRegistry(op, registryPath string) (func(), error) {
+	releaseLock, err := util.AcquireLock(registryPath + constant.LOCK_FILE_EXTENSION, registryLockTimeout,
+		registryLockStaleAfter)
+	if err != nil {
+		return nil, newError(op, err, "Error occurred while locking the registry.")
+	}
+	return releaseLock, nil
+}
+
+// This function will read the registry at the given location. An empty registry is returned if the file does not
+// exist.
+func loadRegistry(registryPath string) (*Registry, error) {
+	registry := &Registry{
+		Updates: make([]RegistryEntry, 0),
+	}
+	data, err := ioutil.ReadFile(registryPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return registry, nil
+		}
+		return nil, err
+	}
+	err = json.Unmarshal(data, registry)
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// This function will save the registry to the given location. The registry is written to a temporary file first and
+// then renamed so that readers never see a partially written registry.
+func saveRegistry(registryPath string, registry *Registry) error {
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+	temporaryPath := registryPath + ".tmp"
+	err = ioutil.WriteFile(temporaryPath, append(data, '\n'), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, registryPath)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestRegistry(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	opts := RegistryOptions{
+		RegistryPath: filepath.Join(root, constant.REGISTRY_FILE),
+	}
+	updateNumber, err := AllocateUpdateNumber(AllocateOptions{
+		PlatformVersion: "4.4.0",
+		RegistryPath: opts.RegistryPath,
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	for _, testCase := range []struct {
+		updateNumber string
+		file         string
+	}{
+		{updateNumber, "repository/components/plugins/foo.jar"},
+		{"0002", "repository/components/plugins/bar.jar"},
+	} {
+		updateName := "WSO2-CARBON-UPDATE-4.4.0-" + testCase.updateNumber
+		updatePath := filepath.Join(root, updateName + ".zip")
+		writeUpdate(t, updatePath, updateName, testCase.updateNumber, "wso2esb-4.9.0", testCase.file)
+		if _, err := AddToRegistry(opts, updatePath); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+
+	entries, err := SearchRegistry(opts, RegistryQuery{})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Test failed. Unexpected entries: %v", entries)
+	}
+	if entries[0].Status != constant.REGISTRY_STATUS_RELEASED || len(entries[0].ReservedBy) == 0 {
+		t.Errorf("Test failed. Reserved entry was not released: %v", entries[0])
+	}
+
+	testCases := []struct {
+		query    RegistryQuery
+		expected []string
+	}{
+		{RegistryQuery{File: "repository/components/plugins/foo.jar"}, []string{"0001"}},
+		{RegistryQuery{File: "bar.jar"}, []string{"0002"}},
+		{RegistryQuery{File: "plugins/baz.jar"}, []string{}},
+		{RegistryQuery{JiraKey: "CARBON-1", PlatformVersion: "4.4.0"}, []string{"0001", "0002"}},
+		{RegistryQuery{PlatformVersion: "5.0.0"}, []string{}},
+	}
+	for _, testCase := range testCases {
+		entries, err := SearchRegistry(opts, testCase.query)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		updateNumbers := make([]string, 0)
+		for _, entry := range entries {
+			updateNumbers = append(updateNumbers, entry.UpdateNumber)
+		}
+		if strings.Join(updateNumbers, ",") != strings.Join(testCase.expected, ",") {
+			t.Errorf("Test failed for %v, expected: %v, actual: %v", testCase.query, testCase.expected, updateNumbers)
+		}
+	}
+
+	buffer := bytes.Buffer{}
+	if err := ExportRegistry(opts, &buffer); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "platform_version,update_number,") {
+		t.Errorf("Test failed. Unexpected CSV: %s", buffer.String())
+	}
+	if !strings.Contains(lines[2], "4.4.0,0002,WSO2-CARBON-UPDATE-4.4.0-0002,released,wilkes,wso2esb-4.9.0,CARBON-1,,,repository/components/plugins/bar.jar") {
+		t.Errorf("Test failed. Unexpected CSV line: %s", lines[2])
+	}
+}
+
+func TestReadRegistrySortsPlatformVersions(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	opts := RegistryOptions{
+		RegistryPath: filepath.Join(root, constant.REGISTRY_FILE),
+	}
+	// 4.10.0 should be sorted after 4.9.0 even though it is smaller as a string
+	for _, platformVersion := range []string{"4.10.0", "4.9.0", "4.4.0"} {
+		if _, err := AllocateUpdateNumber(AllocateOptions{
+			PlatformVersion: platformVersion,
+			RegistryPath: opts.RegistryPath,
+		}); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+	registry, err := readRegistry(opts)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	platformVersions := make([]string, 0)
+	for _, entry := range registry.Updates {
+		platformVersions = append(platformVersions, entry.PlatformVersion)
+	}
+	expected := "4.4.0,4.9.0,4.10.0"
+	if strings.Join(platformVersions, ",") != expected {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, strings.Join(platformVersions, ","))
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"path/filepath"
+)
+
+// struct which is used to report the result of validating an update in machine readable formats such as JSON
+type Report struct {
+	// Location of the validated update
+	Update      string       `json:"update"`
+	Valid       bool         `json:"valid"`
+	// Reason for the failure. This is nil if the update is valid.
+	Error       *Error       `json:"error,omitempty"`
+	Warnings    []string     `json:"warnings"`
+	FileChanges *FileChanges `json:"file_changes,omitempty"`
+}
+
+// NewReport will create a report using the result and the error returned by Validate.
+func NewReport(updateFilePath string, result *Result, err error) *Report {
+	report := &Report{
+		Update: filepath.ToSlash(updateFilePath),
+		Valid: err == nil,
+		Warnings: []string{},
+	}
+	if err != nil {
+		updateError, ok := err.(*Error)
+		if !ok {
+			updateError = &Error{Op: opValidate, Err: err}
+		}
+		report.Error = updateError
+	}
+	if result != nil {
+		report.Warnings = append(report.Warnings, result.Warnings...)
+		if err == nil {
+			fileChanges := result.FileChanges
+			report.FileChanges = &fileChanges
+		}
+	}
+	return report
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+// struct which is used to pass the values required to validate an update directory before creating the update zip
+type SourceOptions struct {
+	// Directory which contains the files of the update
+	UpdateDirectory   string
+	// Location of the distribution zip
+	DistributionPath  string
+	ResourceFiles     ResourceFiles
+	ProductCatalog    map[string]map[string]string
+	// Whether placeholders in the update-descriptor.yaml should be reported as warnings instead of errors
+	AllowPlaceholders bool
+}
+
+// ValidateSource will validate the given update directory without creating the update zip. The update-descriptor.yaml
+// and the resource files are validated in the same way as an update zip. Files which are not found or found in multiple
+// locations in the distribution are reported as warnings because 'create' would prompt the user for them.
+func ValidateSource(opts SourceOptions) (*Result, error) {
+	result := &Result{}
+	opts.ResourceFiles = opts.ResourceFiles.getProductResourceFiles(getProductName(opts.DistributionPath))
+
+	updateDescriptor, _, err := readUpdateDirectory(opValidate, opts.UpdateDirectory, opts.DistributionPath, opts.ProductCatalog, opts.AllowPlaceholders, result)
+	if err != nil {
+		return nil, err
+	}
+	updateName := getUpdateName(updateDescriptor, constant.UPDATE_NAME_PREFIX)
+	result.UpdateName = updateName
+	result.UpdateNumber = updateDescriptor.Update_number
+	result.PlatformVersion = updateDescriptor.Platform_version
+	result.setFileChanges(updateDescriptor)
+	ctx := &runContext{
+		updateRoot: strings.TrimSuffix(opts.UpdateDirectory, constant.PATH_SEPARATOR),
+		updateName: updateName,
+		productName: getProductName(opts.DistributionPath),
+	}
+
+	// Check the content of the resource files which are checked in update zips as well
+	isASecPatch := false
+	isNotAContributionFileFound := false
+	for _, filename := range []string{constant.UPDATE_DESCRIPTOR_FILE, constant.LICENSE_FILE, constant.INSTRUCTIONS_FILE,
+		constant.INSTRUCTIONS_YAML_FILE, constant.NOT_A_CONTRIBUTION_FILE} {
+		data, err := ioutil.ReadFile(filepath.Join(ctx.updateRoot, filename))
+		if err != nil {
+			logger.Debug(fmt.Sprintf("Skipping checking '%s'. %v", filename, err))
+			continue
+		}
+		checkFileContent(data, filename, result)
+		switch filename {
+		case constant.LICENSE_FILE:
+			isASecPatch = strings.Contains(string(data), "under Apache License 2.0")
+		case constant.INSTRUCTIONS_YAML_FILE:
+			ctx.instructions, err = util.UnmarshalInstructions(data)
+			if err != nil {
+				return result, newErrorWithCode(opValidate, CodeInstructionsInvalid, err, "'" + constant.INSTRUCTIONS_YAML_FILE + "' is invalid.")
+			}
+		case constant.NOT_A_CONTRIBUTION_FILE:
+			isNotAContributionFileFound = true
+		}
+	}
+	checkNotAContributionFile(isASecPatch, isNotAContributionFileFound, result)
+
+	// Check whether all mandatory resource files are available
+	ignorePatterns, err := util.LoadIgnorePatterns(filepath.Join(ctx.updateRoot, constant.IGNORE_FILE))
+	if err != nil {
+		return result, newError(opValidate, err, fmt.Sprintf("Error occurred while reading '%s'.", constant.IGNORE_FILE))
+	}
+	_, missingFiles, missingOptionalFiles, err := findResourceFiles(ctx.updateRoot, opts.ResourceFiles, ignorePatterns)
+	if err != nil {
+		return result, newError(opValidate, err, "Error occurred while reading update directory.")
+	}
+	if len(missingOptionalFiles) > 0 {
+		result.addMessage(fmt.Sprintf("Optional resource files not found in the update directory: %s",
+			strings.Join(missingOptionalFiles, ", ")))
+	}
+
+	// Find the matches of the files and directories in the root of the update directory. Create command prompts the
+	// user if there are no matches or multiple matches.
+	_, rootLevelDirectoriesMap, rootLevelFilesMap, err := readDirectory(ctx.updateRoot, opts.ResourceFiles.getIgnoredFiles(), ignorePatterns, result)
+	if err != nil {
+		return result, newError(opValidate, err, "Error occurred while reading update directory.")
+	}
+	rootNode, err := readZip(ctx, opts.DistributionPath)
+	if err != nil {
+		return result, newErrorWithCode(opValidate, CodeDistributionUnreadable, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.DistributionPath))
+	}
+	checkMatches(&rootNode, rootLevelDirectoriesMap, true, result)
+	checkMatches(&rootNode, rootLevelFilesMap, false, result)
+	if ctx.instructions != nil {
+		err = checkConfigFiles(opValidate, ctx.instructions, updateDescriptor, func(configFile string) bool {
+			return PathExists(&rootNode, configFile, false)
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	if len(missingFiles) > 0 {
+		return result, newError(opValidate, nil, fmt.Sprintf("Mandatory resource files not found in '%s': %s",
+			opts.UpdateDirectory, strings.Join(missingFiles, ", ")))
+	}
+	return result, nil
+}
+
+// This function will add a warning for each of the given files or directories which are not found or found in multiple
+// locations in the distribution.
+func checkMatches(rootNode *node, filesMap map[string]bool, isDir bool, result *Result) {
+	filenames := make([]string, 0)
+	for filename := range filesMap {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		matches := make(map[string]*node)
+		FindMatches(rootNode, filename, isDir, matches)
+		switch len(matches) {
+		case 0:
+			result.addWarning(fmt.Sprintf("'%s' not found in the distribution. 'create' will ask whether to add it as a new file.", filename))
+		case 1:
+			logger.Debug(fmt.Sprintf("Single match found for '%s'.", filename))
+		default:
+			locations := make([]string, 0)
+			for location := range matches {
+				locations = append(locations, location)
+			}
+			sort.Strings(locations)
+			result.addWarning(fmt.Sprintf("Multiple matches found for '%s' in the distribution: %s. 'create' will ask to select the locations.",
+				filename, strings.Join(locations, ", ")))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestValidateSource(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	distributionPath := filepath.Join(root, "wso2esb-4.9.0.zip")
+	writeZip(t, distributionPath, map[string]string{
+		"wso2esb-4.9.0/repository/components/plugins/a.jar": "a",
+		"wso2esb-4.9.0/repository/components/dropins/b.jar": "b",
+		"wso2esb-4.9.0/lib/b.jar": "b",
+	})
+	updateDirectory := filepath.Join(root, "update")
+	files := map[string]string{
+		constant.UPDATE_DESCRIPTOR_FILE: `update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  CARBON-1: Fix something
+description: Fixes something.
+`,
+		constant.LICENSE_FILE: "license",
+		"a.jar": "updated a",
+		"b.jar": "updated b",
+		"c.jar": "new",
+	}
+	if err := os.Mkdir(updateDirectory, 0700); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(updateDirectory, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+	opts := SourceOptions{
+		UpdateDirectory: updateDirectory,
+		DistributionPath: distributionPath,
+		ResourceFiles: ResourceFiles{
+			Mandatory: []string{constant.UPDATE_DESCRIPTOR_FILE, constant.LICENSE_FILE},
+		},
+	}
+
+	result, err := ValidateSource(opts)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	warnings := strings.Join(result.Warnings, "\n")
+	for _, expected := range []string{"Multiple matches found for 'b.jar'", "'c.jar' not found in the distribution"} {
+		if !strings.Contains(warnings, expected) {
+			t.Errorf("Test failed. Expected a warning containing '%s'. Warnings: %s", expected, warnings)
+		}
+	}
+	if strings.Contains(warnings, "'a.jar'") {
+		t.Errorf("Test failed. Unexpected warning for 'a.jar'. Warnings: %s", warnings)
+	}
+
+	if err := os.Remove(filepath.Join(updateDirectory, constant.LICENSE_FILE)); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if _, err := ValidateSource(opts); err == nil || !strings.Contains(err.Error(), constant.LICENSE_FILE) {
+		t.Errorf("Test failed. Expected an error for the missing '%s'. Actual: %v", constant.LICENSE_FILE, err)
+	}
+}
+
+func TestValidateSourceWithResourcePatterns(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	distributionPath := filepath.Join(root, "wso2am-2.0.0.zip")
+	writeZip(t, distributionPath, map[string]string{
+		"wso2am-2.0.0/repository/components/plugins/a.jar": "a",
+	})
+	updateDirectory := filepath.Join(root, "update")
+	files := map[string]string{
+		constant.UPDATE_DESCRIPTOR_FILE: `update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2am-2.0.0
+bug_fixes:
+  CARBON-1: Fix something
+description: Fixes something.
+`,
+		constant.LICENSE_FILE: "license",
+		"CHANGES.md": "changes",
+		"a.jar": "updated a",
+		"docs/guide/setup.txt": "docs",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(updateDirectory, name)), 0700); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(updateDirectory, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+	opts := SourceOptions{
+		UpdateDirectory: updateDirectory,
+		DistributionPath: distributionPath,
+		ResourceFiles: ResourceFiles{
+			Mandatory: []string{constant.UPDATE_DESCRIPTOR_FILE, constant.LICENSE_FILE},
+			Optional: []string{constant.INSTRUCTIONS_FILE},
+			Skip: []string{"*.md"},
+			Products: map[string]ResourceFiles{
+				"wso2am": {Optional: []string{"docs/**"}},
+				"wso2am-2.0.0": {Mandatory: []string{"RELEASE_NOTES.txt"}},
+			},
+		},
+	}
+
+	// docs and CHANGES.md should not be matched against the distribution
+	result, err := ValidateSource(opts)
+	if err == nil || !strings.Contains(err.Error(), "RELEASE_NOTES.txt") {
+		t.Fatalf("Test failed. Expected an error for the missing 'RELEASE_NOTES.txt'. Actual: %v", err)
+	}
+	messages := strings.Join(result.Messages, "\n")
+	if !strings.Contains(messages, "Optional resource files not found in the update directory: " + constant.INSTRUCTIONS_FILE) {
+		t.Errorf("Test failed. Unexpected messages: %s", messages)
+	}
+	warnings := strings.Join(result.Warnings, "\n")
+	if strings.Contains(warnings, "docs") || strings.Contains(warnings, "CHANGES.md") {
+		t.Errorf("Test failed. Unexpected warnings: %s", warnings)
+	}
+
+	if !opts.ResourceFiles.getProductResourceFiles("wso2am-2.0.0").isResourceFile("docs/guide/setup.txt") {
+		t.Errorf("Test failed. Expected 'docs/guide/setup.txt' to be a resource file.")
+	}
+	if opts.ResourceFiles.getProductResourceFiles("wso2esb-4.9.0").isResourceFile("docs/guide/setup.txt") {
+		t.Errorf("Test failed. Expected 'docs/guide/setup.txt' not to be a resource file.")
+	}
+	if opts.ResourceFiles.isResourceFile("lib/" + constant.LICENSE_FILE) {
+		t.Errorf("Test failed. Patterns without a / should match the files in the root only.")
+	}
+}
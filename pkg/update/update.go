@@ -0,0 +1,175 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+// Package update contains the logic used to initialize, create and validate updates. Functions in this package do not
+// print to the console or exit the process. They return a result and an error so that they can be used by other Go
+// tools as well as the wum-uc commands.
+package update
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ian-kent/go-log/log"
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+var logger = log.Logger()
+
+// struct which is used to store the patterns of the resource files of an update. Patterns use the gitignore syntax.
+// Patterns without a / match the files in the root of the update directory (Ex: *.md) and the other patterns are relative
+// to the update directory (Ex: docs/**).
+type ResourceFiles struct {
+	// Files which must be in the update
+	Mandatory []string
+	// Files which are copied to the update if they are available
+	Optional  []string
+	// Files which are not copied to the update
+	Skip      []string
+	// Patterns which are added to the above patterns for a product. Key is the product name (Ex: wso2am) or the
+	// product name and the version (Ex: wso2am-2.0.0).
+	Products  map[string]ResourceFiles
+}
+
+// struct which is used to return the details of an update after initializing, creating or validating it
+type Result struct {
+	// Name of the update. Ex: WSO2-CARBON-UPDATE-4.4.0-0001
+	UpdateName      string
+	// Update number and platform version in the update-descriptor.yaml
+	UpdateNumber    string
+	PlatformVersion string
+	// Location of the created update zip or the update-descriptor.yaml created by Init
+	OutputPath      string
+	// Location of the detached signature if the update was signed
+	SignaturePath   string
+	// Files added, modified and removed by the update
+	FileChanges     FileChanges
+	// Informational messages which should be shown to the user
+	Messages        []string
+	// Issues which did not stop the process but should be reviewed by the user
+	Warnings        []string
+}
+
+// struct which is used to store the file changes of an update
+type FileChanges struct {
+	AddedFiles    []string `json:"added_files"`
+	RemovedFiles  []string `json:"removed_files"`
+	ModifiedFiles []string `json:"modified_files"`
+}
+
+// struct which is used to hold the state of a single create or validate run. A new context is created for each run so
+// that multiple updates can be processed concurrently in the same process.
+type runContext struct {
+	// Directory which contains the files of the update being created
+	updateRoot       string
+	// Name of the update. Ex: WSO2-CARBON-UPDATE-4.4.0-0001
+	updateName       string
+	// Name of the product distribution. Ex: wso2esb-4.9.0
+	productName      string
+	// Directory where the update is staged before it is zipped
+	stagingDirectory string
+	// Structured instructions of the update. This is nil if the update does not have an instructions.yaml.
+	instructions     *util.Instructions
+	// Structured diffs of the configuration files. This is nil if the update does not have a conf-diffs.yaml.
+	confDiffs        *util.ConfDiffs
+}
+
+// This function will add an informational message to the result.
+func (result *Result) addMessage(message string) {
+	logger.Debug(fmt.Sprintf("[MESSAGE] %s", message))
+	result.Messages = append(result.Messages, message)
+}
+
+// This function will add a warning to the result.
+func (result *Result) addWarning(warning string) {
+	logger.Debug(fmt.Sprintf("[WARNING] %s", warning))
+	result.Warnings = append(result.Warnings, warning)
+}
+
+// This function will set the file changes of the result using the given update descriptor.
+func (result *Result) setFileChanges(updateDescriptor *util.UpdateDescriptor) {
+	result.FileChanges = FileChanges{
+		AddedFiles: updateDescriptor.File_changes.Added_files,
+		RemovedFiles: updateDescriptor.File_changes.Removed_files,
+		ModifiedFiles: updateDescriptor.File_changes.Modified_files,
+	}
+}
+
+// This will return the patterns of the files which would be ignored when reading the update directory. The .wumucignore
+// is ignored as well. Patterns are matched in the same way as matchesResourcePattern, so only the resource files which
+// are copied to the update and the skipped files are ignored.
+func (resourceFiles ResourceFiles) getIgnoredFiles() *util.IgnorePatterns {
+	patterns := make([]string, 0)
+	patterns = append(patterns, resourceFiles.Mandatory...)
+	patterns = append(patterns, resourceFiles.Optional...)
+	patterns = append(patterns, resourceFiles.Skip...)
+	patterns = append(patterns, constant.IGNORE_FILE)
+	for i, pattern := range patterns {
+		patterns[i] = getRootPattern(pattern)
+	}
+	return util.ParseIgnorePatterns([]byte(strings.Join(patterns, "\n")))
+}
+
+// This will check whether the given path relative to the root of the update is a mandatory or an optional resource file.
+func (resourceFiles ResourceFiles) isResourceFile(relativePath string) bool {
+	for _, pattern := range append(resourceFiles.Mandatory, resourceFiles.Optional...) {
+		if matchesResourcePattern(pattern, relativePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// This will return the resource files of the given product. The patterns of the product name and the patterns of the
+// product name and the version are added to the common patterns. Ex: wso2am-2.0.0
+func (resourceFiles ResourceFiles) getProductResourceFiles(productName string) ResourceFiles {
+	productResourceFiles := ResourceFiles{
+		Mandatory: append([]string{}, resourceFiles.Mandatory...),
+		Optional: append([]string{}, resourceFiles.Optional...),
+		Skip: append([]string{}, resourceFiles.Skip...),
+	}
+	keys := []string{productName}
+	if matches := productNameRegex.FindStringSubmatch(productName); matches != nil {
+		keys = []string{matches[1], productName}
+	}
+	for _, key := range keys {
+		rules, found := resourceFiles.Products[key]
+		if !found {
+			continue
+		}
+		logger.Debug(fmt.Sprintf("Adding resource files of '%s': %v", key, rules))
+		productResourceFiles.Mandatory = append(productResourceFiles.Mandatory, rules.Mandatory...)
+		productResourceFiles.Optional = append(productResourceFiles.Optional, rules.Optional...)
+		productResourceFiles.Skip = append(productResourceFiles.Skip, rules.Skip...)
+	}
+	return productResourceFiles
+}
+
+// This is used to get the product name from the name of a distribution. Ex: wso2am from wso2am-2.0.0
+var productNameRegex = regexp.MustCompile(`^(.+)-(\d[^-]*)$`)
+
+// This function will check whether the given path relative to the root of the update matches the given resource file
+// pattern. Patterns without a / match the files in the root only.
+func matchesResourcePattern(pattern, relativePath string) bool {
+	return util.ParseIgnorePatterns([]byte(getRootPattern(pattern))).IsIgnored(relativePath, false)
+}
+
+// This function will anchor the given resource file pattern to the root of the update directory if it does not have a
+// /. Ex: *.md matches README.md but not docs/README.md
+func getRootPattern(pattern string) string {
+	if !strings.Contains(strings.TrimSuffix(pattern, "/"), "/") {
+		return "/" + pattern
+	}
+	return pattern
+}
+
+// This function will validate the products in the applies_to field against the given product catalog. Products are not
+// validated if the product catalog is empty.
+func validateProducts(updateDescriptor *util.UpdateDescriptor, productCatalog map[string]map[string]string) error {
+	if len(productCatalog) == 0 {
+		logger.Debug("Product catalog is empty. Skipping validating products.")
+		return nil
+	}
+	return util.ValidateProducts(updateDescriptor.Applies_to, updateDescriptor.Platform_version, productCatalog)
+}
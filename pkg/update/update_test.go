@@ -0,0 +1,459 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+)
+
+func TestValidateWithInvalidFileName(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	updatePath := filepath.Join(root, "update.zip")
+	distributionPath := filepath.Join(root, "wso2esb-4.9.0.zip")
+	for _, file := range []string{updatePath, distributionPath} {
+		if err := ioutil.WriteFile(file, []byte{}, 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+
+	result, err := Validate(ValidateOptions{
+		UpdateFilePath: updatePath,
+		DistributionPath: distributionPath,
+	})
+	if result != nil {
+		t.Errorf("Test failed, expected: nil result, actual: %v", result)
+	}
+	updateError, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("Test failed, expected: *Error, actual: %T", err)
+	}
+	if updateError.Op != opValidate {
+		t.Errorf("Test failed, expected: %s, actual: %s", opValidate, updateError.Op)
+	}
+	if updateError.Code != CodeBadFilename {
+		t.Errorf("Test failed, expected: %s, actual: %s", CodeBadFilename, updateError.Code)
+	}
+}
+
+func TestInit(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	directory := filepath.Join(root, "update")
+	if _, err := Init(InitOptions{Directory: directory}); err == nil {
+		t.Error("Test failed. Expected an error when the directory does not exist")
+	}
+
+	result, err := Init(InitOptions{
+		Directory: directory,
+		CreateDirectory: true,
+		Customize: func(updateDescriptor *util.UpdateDescriptor) error {
+			updateDescriptor.Update_number = "0001"
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	expected := filepath.Join(directory, constant.UPDATE_DESCRIPTOR_FILE)
+	if result.OutputPath != expected {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, result.OutputPath)
+	}
+	updateDescriptor, err := util.LoadUpdateDescriptor(constant.UPDATE_DESCRIPTOR_FILE, directory)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if updateDescriptor.Update_number != "0001" {
+		t.Errorf("Test failed, expected: %s, actual: %s", "0001", updateDescriptor.Update_number)
+	}
+}
+
+func TestValidateConcurrently(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	// Each update modifies a file which is only available in its own distribution. So validation fails if the state
+	// of one run is used by another run.
+	testCases := []struct {
+		product      string
+		updateNumber string
+		file         string
+	}{
+		{"wso2esb-4.9.0", "0001", "repository/components/plugins/a.jar"},
+		{"wso2am-1.10.0", "0002", "repository/components/plugins/b.jar"},
+	}
+	options := make([]ValidateOptions, 0)
+	for _, testCase := range testCases {
+		distributionPath := filepath.Join(root, testCase.product + ".zip")
+		writeDistribution(t, distributionPath, testCase.product, testCase.file)
+		updateName := constant.UPDATE_NAME_PREFIX + "-4.4.0-" + testCase.updateNumber
+		updatePath := filepath.Join(root, updateName + ".zip")
+		writeUpdate(t, updatePath, updateName, testCase.updateNumber, testCase.product, testCase.file)
+		options = append(options, ValidateOptions{
+			UpdateFilePath: updatePath,
+			DistributionPath: distributionPath,
+		})
+	}
+
+	var waitGroup sync.WaitGroup
+	errorChannel := make(chan error, 10 * len(options))
+	for i := 0; i < 10; i++ {
+		for _, option := range options {
+			waitGroup.Add(1)
+			go func(option ValidateOptions) {
+				defer waitGroup.Done()
+				if _, err := Validate(option); err != nil {
+					errorChannel <- err
+				}
+			}(option)
+		}
+	}
+	waitGroup.Wait()
+	close(errorChannel)
+	for err := range errorChannel {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	product := "wso2esb-4.9.0"
+	file := "repository/components/plugins/a.jar"
+	distributionPath := filepath.Join(root, product + ".zip")
+	writeDistribution(t, distributionPath, product, file)
+	updatesDirectory := filepath.Join(root, "updates")
+	if err := os.Mkdir(updatesDirectory, 0700); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	// 0003 uses the update number of 0001. 0002 is not valid. README.txt is not an update and should be ignored.
+	writeUpdate(t, filepath.Join(updatesDirectory, "WSO2-CARBON-UPDATE-4.4.0-0001.zip"), "WSO2-CARBON-UPDATE-4.4.0-0001", "0001", product, file)
+	writeUpdate(t, filepath.Join(updatesDirectory, "WSO2-CARBON-UPDATE-4.4.0-0002.zip"), "WSO2-CARBON-UPDATE-4.4.0-0002", "0002", product, "unknown.jar")
+	writeUpdate(t, filepath.Join(updatesDirectory, "WSO2-CARBON-UPDATE-4.4.0-0003.zip"), "WSO2-CARBON-UPDATE-4.4.0-0003", "0001", product, file)
+	if err := ioutil.WriteFile(filepath.Join(updatesDirectory, constant.README_FILE), []byte{}, 0600); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	batchReport, err := ValidateBatch(BatchOptions{
+		UpdatesDirectory: updatesDirectory,
+		DistributionPath: distributionPath,
+		Workers: 2,
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if batchReport.Total != 3 || batchReport.Passed != 0 || batchReport.Failed != 3 {
+		t.Errorf("Test failed. Unexpected totals. Total: %d, Passed: %d, Failed: %d", batchReport.Total,
+			batchReport.Passed, batchReport.Failed)
+	}
+	expectedCodes := []ErrorCode{CodeDuplicateUpdateNumber, CodeFileNotInDistribution, CodeDuplicateUpdateNumber}
+	for i, report := range batchReport.Updates {
+		if report.Error == nil || report.Error.Code != expectedCodes[i] {
+			t.Errorf("Test failed for %s, expected: %s, actual: %v", report.Update, expectedCodes[i], report.Error)
+		}
+	}
+	if len(batchReport.Duplicates) != 1 || len(batchReport.Duplicates[0].Updates) != 2 {
+		t.Errorf("Test failed. Unexpected duplicates: %v", batchReport.Duplicates)
+	}
+}
+
+func TestValidateWithPlaceholders(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	product := "wso2esb-4.9.0"
+	file := "repository/components/plugins/a.jar"
+	distributionPath := filepath.Join(root, product + ".zip")
+	writeDistribution(t, distributionPath, product, file)
+	updateName := "WSO2-CARBON-UPDATE-4.4.0-0001"
+	updatePath := filepath.Join(root, updateName + ".zip")
+	writeZip(t, updatePath, map[string]string{
+		updateName + "/" + constant.UPDATE_DESCRIPTOR_FILE: `update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  ADD_JIRA_KEY_HERE: ADD_JIRA_SUMMARY_HERE
+description: Fixes something.
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files:
+  - repository/components/plugins/a.jar
+`,
+		updateName + "/" + constant.CARBON_HOME + "/" + file: "updated",
+	})
+
+	_, err = Validate(ValidateOptions{
+		UpdateFilePath: updatePath,
+		DistributionPath: distributionPath,
+	})
+	if GetErrorCode(err) != CodePlaceholderFound {
+		t.Fatalf("Test failed, expected: %s, actual: %v", CodePlaceholderFound, err)
+	}
+	expected := "Line 6: 'ADD_JIRA_KEY_HERE' in 'bug_fixes'"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, err.Error())
+	}
+
+	result, err := Validate(ValidateOptions{
+		UpdateFilePath: updatePath,
+		DistributionPath: distributionPath,
+		AllowPlaceholders: true,
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(result.Warnings) < 2 || !strings.Contains(result.Warnings[0], "'bug_fixes' field at line 6") {
+		t.Errorf("Test failed. Unexpected warnings: %v", result.Warnings)
+	}
+}
+
+func TestValidateWithInstructions(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	product := "wso2esb-4.9.0"
+	file := "repository/conf/carbon.xml"
+	distributionPath := filepath.Join(root, product + ".zip")
+	writeDistribution(t, distributionPath, product, file)
+	updateName := "WSO2-CARBON-UPDATE-4.4.0-0001"
+	updatePath := filepath.Join(root, updateName + ".zip")
+
+	testCases := []struct {
+		instructions string
+		expected     ErrorCode
+	}{
+		{"config_changes:\n- file: repository/conf/carbon.xml\n  description: Set the HostName.\nrestart_required: true\n", ""},
+		{"config_changes:\n- file: repository/conf/axis2/axis2.xml\n  description: Enable the transport.\n", CodeInstructionsInvalid},
+		{"restart: true\n", CodeInstructionsInvalid},
+	}
+	for _, testCase := range testCases {
+		writeZip(t, updatePath, map[string]string{
+			updateName + "/" + constant.UPDATE_DESCRIPTOR_FILE: `update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  CARBON-1: Fix something
+description: Fixes something.
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files:
+  - repository/conf/carbon.xml
+`,
+			updateName + "/" + constant.INSTRUCTIONS_YAML_FILE: testCase.instructions,
+			updateName + "/" + constant.CARBON_HOME + "/" + file: "updated",
+		})
+
+		_, err = Validate(ValidateOptions{
+			UpdateFilePath: updatePath,
+			DistributionPath: distributionPath,
+		})
+		if GetErrorCode(err) != testCase.expected {
+			t.Errorf("Test failed, expected: %s, actual: %v", testCase.expected, err)
+		}
+	}
+}
+
+func TestValidateWithConfDiffs(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	product := "wso2esb-4.9.0"
+	file := "repository/conf/carbon.xml"
+	distributionPath := filepath.Join(root, product + ".zip")
+	writeZip(t, distributionPath, map[string]string{
+		product + "/" + file: "<Server><Ports><Offset>0</Offset></Ports></Server>",
+	})
+	updateName := "WSO2-CARBON-UPDATE-4.4.0-0001"
+	updatePath := filepath.Join(root, updateName + ".zip")
+
+	testCases := []struct {
+		old      string
+		expected ErrorCode
+	}{
+		{"0", ""},
+		{"2", CodeConfDiffConflict},
+	}
+	for _, testCase := range testCases {
+		writeZip(t, updatePath, map[string]string{
+			updateName + "/" + constant.UPDATE_DESCRIPTOR_FILE: `update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  CARBON-1: Fix something
+description: Fixes something.
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files:
+  - repository/conf/carbon.xml
+`,
+			updateName + "/" + constant.CONF_DIFFS_FILE: `files:
+- file: repository/conf/carbon.xml
+  format: xml
+  changes:
+  - op: set
+    path: /Server/Ports/Offset
+    old: "` + testCase.old + `"
+    value: "1"
+`,
+			updateName + "/" + constant.CARBON_HOME + "/" + file: "<Server><Ports><Offset>1</Offset></Ports></Server>",
+		})
+
+		_, err = Validate(ValidateOptions{
+			UpdateFilePath: updatePath,
+			DistributionPath: distributionPath,
+		})
+		if GetErrorCode(err) != testCase.expected {
+			t.Errorf("Test failed, expected: %s, actual: %v", testCase.expected, err)
+		}
+	}
+}
+
+func TestValidateWithJunkFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	product := "wso2esb-4.9.0"
+	file := "repository/components/plugins/a.jar"
+	distributionPath := filepath.Join(root, product + ".zip")
+	writeDistribution(t, distributionPath, product, file)
+	updateName := "WSO2-CARBON-UPDATE-4.4.0-0001"
+	updatePath := filepath.Join(root, updateName + ".zip")
+	writeUpdate(t, updatePath, updateName, "0001", product, file)
+	files := map[string]string{
+		updateName + "/" + constant.CARBON_HOME + "/" + file: "updated",
+		updateName + "/" + constant.CARBON_HOME + "/repository/.DS_Store": "junk",
+		updateName + "/.git/HEAD": "junk",
+		updateName + "/.git/config": "junk",
+		updateName + "/build.log": "junk",
+	}
+	data, _, err := util.ReadFileFromUpdateZip(updatePath, constant.UPDATE_DESCRIPTOR_FILE)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	files[updateName + "/" + constant.UPDATE_DESCRIPTOR_FILE] = string(data)
+	writeZip(t, updatePath, files)
+
+	// build.log is not in the default patterns
+	_, err = Validate(ValidateOptions{
+		UpdateFilePath: updatePath,
+		DistributionPath: distributionPath,
+	})
+	if GetErrorCode(err) != CodeUnknownFile {
+		t.Fatalf("Test failed, expected: %s, actual: %v", CodeUnknownFile, err)
+	}
+
+	ignoreFile := filepath.Join(root, constant.IGNORE_FILE)
+	err = ioutil.WriteFile(ignoreFile, []byte("*.log\n"), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	result, err := Validate(ValidateOptions{
+		UpdateFilePath: updatePath,
+		DistributionPath: distributionPath,
+		IgnoreFile: ignoreFile,
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	junkFiles := 0
+	for _, warning := range result.Warnings {
+		if strings.HasPrefix(warning, "Junk file found") {
+			junkFiles++
+		}
+	}
+	if junkFiles != 3 {
+		t.Errorf("Test failed, expected: %d, actual: %v", 3, result.Warnings)
+	}
+}
+
+// This function will create a distribution zip which contains the given file.
+func writeDistribution(t *testing.T, location, product, file string) {
+	writeZip(t, location, map[string]string{
+		product + "/" + file: "original",
+	})
+}
+
+// This function will create an update zip which modifies the given file.
+func writeUpdate(t *testing.T, location, updateName, updateNumber, product, file string) {
+	writeZip(t, location, map[string]string{
+		updateName + "/" + constant.UPDATE_DESCRIPTOR_FILE: fmt.Sprintf(`update_number: %s
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: %s
+bug_fixes:
+  CARBON-1: Fix something
+description: Fixes something.
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files:
+  - %s
+`, updateNumber, product, file),
+		updateName + "/" + constant.CARBON_HOME + "/" + file: "updated",
+	})
+}
+
+// This function will create a zip file at the given location with the given files and their content.
+func writeZip(t *testing.T, location string, files map[string]string) {
+	file, err := os.Create(location)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer file.Close()
+	zipWriter := zip.NewWriter(file)
+	for name, content := range files {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+}
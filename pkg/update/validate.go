@@ -0,0 +1,577 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package update
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"github.com/wso2/wum-uc/util"
+	"gopkg.in/yaml.v2"
+)
+
+// struct which is used to pass the values required to validate an update
+type ValidateOptions struct {
+	// Location of the update zip
+	UpdateFilePath        string
+	// Location of the distribution zip
+	DistributionPath      string
+	// Public key used to verify the signature of the update. Signature is not verified if this is empty.
+	VerificationKey       string
+	// Directory of known updates used to check the 'requires' and 'supersedes' fields. Fields are not checked if this
+	// is empty.
+	KnownUpdatesDirectory string
+	ResourceFiles         ResourceFiles
+	ProductCatalog        map[string]map[string]string
+	// Whether placeholders in the update-descriptor.yaml should be reported as warnings instead of errors
+	AllowPlaceholders     bool
+	// Location of a .wumucignore file. Files in the update zip which match the patterns are reported as junk files and
+	// are not validated. Only the default patterns are used if this is empty or the file does not exist.
+	IgnoreFile            string
+	// Patterns used instead of the IgnoreFile. This is used to read the patterns only once when validating multiple
+	// updates. IgnoreFile is read if this is nil.
+	ignorePatterns        *util.IgnorePatterns
+	// Files in the distribution. This is used to read the distribution only once when validating multiple updates.
+	// Distribution is read if this is nil.
+	distributionFileMap   map[string]bool
+}
+
+// Validate will validate the given update zip. Files in the update are matched against the given distribution and the
+// update-descriptor.yaml is validated as well.
+func Validate(opts ValidateOptions) (*Result, error) {
+	result := &Result{}
+	opts.ResourceFiles = opts.ResourceFiles.getProductResourceFiles(getProductName(opts.DistributionPath))
+
+	//Check whether the update has the zip extension
+	if !strings.HasSuffix(opts.UpdateFilePath, ".zip") {
+		return nil, newErrorWithCode(opValidate, CodeBadFilename, nil, fmt.Sprintf("Update must be a zip file. Entered file '%s' does not have a zip extension.", opts.UpdateFilePath))
+	}
+
+	//Check whether the update file exists
+	exists, err := util.IsFileExists(opts.UpdateFilePath)
+	if err != nil {
+		return nil, newError(opValidate, err, "")
+	}
+	if !exists {
+		return nil, newError(opValidate, nil, fmt.Sprintf("Entered update file does not exist at '%s'.", opts.UpdateFilePath))
+	}
+
+	//Check whether the distribution has the zip extension
+	if !strings.HasSuffix(opts.DistributionPath, ".zip") {
+		return nil, newErrorWithCode(opValidate, CodeDistributionUnreadable, nil, fmt.Sprintf("Distribution must be a zip file. Entered file '%s' does not have a zip extension.", opts.DistributionPath))
+	}
+
+	//Check whether the distribution file exists
+	exists, err = util.IsFileExists(opts.DistributionPath)
+	if err != nil {
+		return nil, newError(opValidate, err, fmt.Sprintf("Error occurred while checking '%s'", opts.DistributionPath))
+	}
+	if !exists {
+		return nil, newErrorWithCode(opValidate, CodeDistributionUnreadable, nil, fmt.Sprintf("Entered distribution file does not exist at '%s'.", opts.DistributionPath))
+	}
+
+	//Check update filename
+	locationInfo, err := os.Stat(opts.UpdateFilePath)
+	if err != nil {
+		return nil, newError(opValidate, err, "Error occurred while getting the information of update file")
+	}
+	match, err := regexp.MatchString(constant.FILENAME_REGEX, locationInfo.Name())
+	if err != nil {
+		return nil, newError(opValidate, err, "")
+	}
+	if !match {
+		return nil, newErrorWithCode(opValidate, CodeBadFilename, nil, fmt.Sprintf("Update filename '%s' does not match '%s' regular expression.", locationInfo.Name(), constant.FILENAME_REGEX))
+	}
+
+	if opts.ignorePatterns == nil {
+		opts.ignorePatterns, err = util.LoadIgnorePatterns(opts.IgnoreFile)
+		if err != nil {
+			return nil, newError(opValidate, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.IgnoreFile))
+		}
+	}
+
+	updateName := strings.TrimSuffix(locationInfo.Name(), ".zip")
+	result.UpdateName = updateName
+	err = validateUpdate(&opts, updateName, result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+//This function will validate the update zip against the distribution. Update name is the name of the root directory of
+//the update zip.
+func validateUpdate(opts *ValidateOptions, updateName string, result *Result) error {
+	ctx := &runContext{
+		updateName: updateName,
+		productName: getProductName(opts.DistributionPath),
+	}
+
+	//Verify the signature of the update if a public key is given
+	if len(opts.VerificationKey) > 0 {
+		err := util.VerifyFileSignature(opts.UpdateFilePath, opts.VerificationKey)
+		if err != nil {
+			return newError(opValidate, err, fmt.Sprintf("Error occurred while verifying the signature of '%s'.", updateName))
+		}
+		result.addMessage(fmt.Sprintf("Signature of '%s' successfully verified.", updateName))
+	}
+
+	//Read the update zip file
+	updateFileMap, updateDescriptor, err := readUpdateZip(ctx, opts.UpdateFilePath, opts, result)
+	if err != nil {
+		return newError(opValidate, err, "")
+	}
+	logger.Trace(fmt.Sprintf("updateFileMap: %v\n", updateFileMap))
+	result.UpdateNumber = updateDescriptor.Update_number
+	result.PlatformVersion = updateDescriptor.Platform_version
+	result.setFileChanges(updateDescriptor)
+
+	//Check the requires and supersedes fields against the known updates
+	if len(opts.KnownUpdatesDirectory) > 0 {
+		err = checkDependencyCycles(updateDescriptor, opts.KnownUpdatesDirectory, result)
+		if err != nil {
+			return newError(opValidate, err, "")
+		}
+	}
+
+	//Read the distribution zip file if it is not already read
+	distributionFileMap := opts.distributionFileMap
+	if distributionFileMap == nil {
+		distributionFileMap, err = readDistributionZip(ctx, opts.DistributionPath)
+		if err != nil {
+			return newErrorWithCode(opValidate, CodeDistributionUnreadable, err, fmt.Sprintf("Error occurred while reading '%s'.", opts.DistributionPath))
+		}
+	}
+	logger.Trace(fmt.Sprintf("distributionFileMap: %v\n", distributionFileMap))
+
+	//Check whether the configuration files in the instructions.yaml exist in the distribution
+	if ctx.instructions != nil {
+		err = checkConfigFiles(opValidate, ctx.instructions, updateDescriptor, func(configFile string) bool {
+			_, found := distributionFileMap[configFile]
+			return found
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	//Check whether the diffs of the configuration files apply to the distribution
+	if ctx.confDiffs != nil {
+		err = checkConfDiffs(ctx, opts.DistributionPath, ctx.confDiffs)
+		if err != nil {
+			return err
+		}
+	}
+
+	//Compare the update with the distribution
+	err = compare(ctx, updateFileMap, distributionFileMap, updateDescriptor, opts)
+	if err != nil {
+		return newError(opValidate, err, "")
+	}
+	return nil
+}
+
+//This function compares the files in the update and the distribution.
+func compare(ctx *runContext, updateFileMap, distributionFileMap map[string]bool, updateDescriptor *util.UpdateDescriptor, opts *ValidateOptions) error {
+	updateName := ctx.updateName
+	for filePath := range updateFileMap {
+		logger.Debug(fmt.Sprintf("Searching: %s", filePath))
+		_, found := distributionFileMap[filePath]
+		if !found {
+			logger.Debug("Added files: ", updateDescriptor.File_changes.Added_files)
+			isInAddedFiles := util.IsStringIsInSlice(filePath, updateDescriptor.File_changes.Added_files)
+			logger.Debug(fmt.Sprintf("isInAddedFiles: %v", isInAddedFiles))
+			fileName := strings.TrimPrefix(filePath, updateName + "/")
+			logger.Debug(fmt.Sprintf("fileName: %s", fileName))
+			foundInResources := opts.ResourceFiles.isResourceFile(fileName)
+			logger.Debug(fmt.Sprintf("found in resources: %v", foundInResources))
+			//check
+			if !isInAddedFiles && !foundInResources {
+				return newErrorWithCode(opValidate, CodeFileNotInDistribution, nil, "File not found in the distribution: '" + filePath + "'. If this is a new file, add an entry to the 'added_files' sections in the '" + constant.UPDATE_DESCRIPTOR_FILE + "' file")
+			} else {
+				logger.Debug("'" + filePath + "' found in added files.")
+			}
+		}
+	}
+	return nil
+}
+
+//This function will check whether the requires and supersedes fields of the given update form cycles with the updates
+// in the given directory. Only the updates which have the same platform version are considered.
+func checkDependencyCycles(updateDescriptor *util.UpdateDescriptor, knownUpdatesDirectory string, result *Result) error {
+	exists, err := util.IsDirectoryExists(knownUpdatesDirectory)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New(fmt.Sprintf("Directory of known updates does not exist at '%s'.", knownUpdatesDirectory))
+	}
+	files, err := ioutil.ReadDir(knownUpdatesDirectory)
+	if err != nil {
+		return err
+	}
+
+	// Build the dependency graph using the known updates
+	dependencyGraph := make(map[string][]string)
+	for _, file := range files {
+		match, err := regexp.MatchString(constant.FILENAME_REGEX, file.Name())
+		if err != nil {
+			return err
+		}
+		if file.IsDir() || !match {
+			continue
+		}
+		knownUpdateDescriptor, err := util.ReadUpdateDescriptorFromZip(filepath.Join(knownUpdatesDirectory, file.Name()))
+		if err != nil {
+			result.addWarning(fmt.Sprintf("Skipping '%s'. %s", file.Name(), err.Error()))
+			continue
+		}
+		if knownUpdateDescriptor.Platform_version != updateDescriptor.Platform_version {
+			continue
+		}
+		dependencyGraph[knownUpdateDescriptor.Update_number] = append(knownUpdateDescriptor.Requires,
+			knownUpdateDescriptor.Supersedes...)
+	}
+	logger.Debug(fmt.Sprintf("knownUpdates: %v", dependencyGraph))
+
+	// Warn about the required updates which are not in the known updates
+	for _, requiredUpdate := range updateDescriptor.Requires {
+		if _, found := dependencyGraph[requiredUpdate]; !found {
+			result.addWarning(fmt.Sprintf("Required update '%s' not found in '%s'.", requiredUpdate, knownUpdatesDirectory))
+		}
+	}
+
+	// Values of the validating update replace the values of the known update with the same update number
+	dependencyGraph[updateDescriptor.Update_number] = append(updateDescriptor.Requires, updateDescriptor.Supersedes...)
+	cycle := util.FindDependencyCycle(updateDescriptor.Update_number, dependencyGraph)
+	if cycle != nil {
+		return errors.New(fmt.Sprintf("'requires' and 'supersedes' fields form a cycle: %s", strings.Join(cycle, " -> ")))
+	}
+	return nil
+}
+
+//This function will read the update zip at the the given location.
+func readUpdateZip(ctx *runContext, filename string, opts *ValidateOptions, result *Result) (map[string]bool, *util.UpdateDescriptor, error) {
+	fileMap := make(map[string]bool)
+	updateDescriptor := util.UpdateDescriptor{}
+
+	isNotAContributionFileFound := false
+	isASecPatch := false
+
+	// Create a reader out of the zip archive
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zipReader.Close()
+
+	updateName := ctx.updateName
+	logger.Debug("updateName:", updateName)
+	// Junk files and directories which are already reported. Files inside a reported directory are not reported.
+	junkFiles := make(map[string]bool)
+	// Directories outside carbon.home are allowed only if they contain resource files. So they are checked at the end.
+	unknownDirectories := make([]string, 0)
+	resourceFilePaths := make([]string, 0)
+	// Iterate through each file/dir found in
+	for _, file := range zipReader.Reader.File {
+		name := getFileName(file.FileInfo().Name())
+		//Check whether the file matches the ignore patterns. Paths are relative to the root directory of the update.
+		ignoredPath := opts.ignorePatterns.GetIgnoredPath(strings.TrimPrefix(filepath.ToSlash(file.Name), updateName + "/"),
+			file.FileInfo().IsDir())
+		if len(ignoredPath) > 0 {
+			if !junkFiles[ignoredPath] {
+				junkFiles[ignoredPath] = true
+				result.addWarning(fmt.Sprintf("Junk file found in the update: '%s'. Please remove it from the update.", ignoredPath))
+			}
+			continue
+		}
+		if file.FileInfo().IsDir() {
+			logger.Debug(fmt.Sprintf("filepath: %s", file.Name))
+
+			logger.Debug(fmt.Sprintf("filename: %s", name))
+			if name != updateName {
+				logger.Debug("Checking:", name)
+				//Check
+				prefix := filepath.Join(updateName, constant.CARBON_HOME)
+				hasPrefix := strings.HasPrefix(file.Name, prefix)
+				if !hasPrefix {
+					unknownDirectories = append(unknownDirectories, file.Name)
+				}
+			}
+		} else {
+			logger.Debug(fmt.Sprintf("file.Name: %s", file.Name))
+			logger.Debug(fmt.Sprintf("file.FileInfo().Name(): %s", name))
+			fullPath := filepath.Join(updateName, name)
+			logger.Debug(fmt.Sprintf("fullPath: %s", fullPath))
+			switch  name{
+			case constant.UPDATE_DESCRIPTOR_FILE:
+				data, err := validateFile(file, constant.UPDATE_DESCRIPTOR_FILE, fullPath, updateName, result)
+				if err != nil {
+					return nil, nil, err
+				}
+				err = checkPlaceholders(opValidate, data, opts.AllowPlaceholders, result)
+				if err != nil {
+					return nil, nil, err
+				}
+				err = unmarshalUpdateDescriptor(data, &updateDescriptor)
+				if err != nil {
+					return nil, nil, newErrorWithCode(opValidate, CodeDescriptorInvalid, err, "'" + constant.UPDATE_DESCRIPTOR_FILE + "' is invalid.")
+				}
+				err = validateProducts(&updateDescriptor, opts.ProductCatalog)
+				if err != nil {
+					return nil, nil, newErrorWithCode(opValidate, CodeDescriptorInvalid, err, "'" + constant.UPDATE_DESCRIPTOR_FILE + "' is invalid.")
+				}
+			case constant.LICENSE_FILE:
+				data, err := validateFile(file, constant.LICENSE_FILE, fullPath, updateName, result)
+				if err != nil {
+					return nil, nil, err
+				}
+				dataString := string(data)
+				if strings.Contains(dataString, "under Apache License 2.0") {
+					isASecPatch = true
+				}
+			case constant.INSTRUCTIONS_FILE:
+				_, err := validateFile(file, constant.INSTRUCTIONS_FILE, fullPath, updateName, result)
+				if err != nil {
+					return nil, nil, err
+				}
+			case constant.INSTRUCTIONS_YAML_FILE:
+				data, err := validateFile(file, constant.INSTRUCTIONS_YAML_FILE, fullPath, updateName, result)
+				if err != nil {
+					return nil, nil, err
+				}
+				ctx.instructions, err = util.UnmarshalInstructions(data)
+				if err != nil {
+					return nil, nil, newErrorWithCode(opValidate, CodeInstructionsInvalid, err, "'" + constant.INSTRUCTIONS_YAML_FILE + "' is invalid.")
+				}
+			case constant.CONF_DIFFS_FILE:
+				// This file is generated by create. So the content is not checked for the word 'patch'.
+				data, err := readResourceFile(file, constant.CONF_DIFFS_FILE, fullPath, updateName)
+				if err != nil {
+					return nil, nil, err
+				}
+				ctx.confDiffs, err = util.UnmarshalConfDiffs(data)
+				if err != nil {
+					return nil, nil, newErrorWithCode(opValidate, CodeConfDiffConflict, err, "'" + constant.CONF_DIFFS_FILE + "' is invalid.")
+				}
+			case constant.NOT_A_CONTRIBUTION_FILE:
+				isNotAContributionFileFound = true
+				_, err := validateFile(file, constant.NOT_A_CONTRIBUTION_FILE, fullPath, updateName, result)
+				if err != nil {
+					return nil, nil, err
+				}
+			default:
+				prefix := filepath.Join(updateName, constant.CARBON_HOME)
+				logger.Debug(fmt.Sprintf("Checking prefix %s in %s", prefix, file.Name))
+				hasPrefix := strings.HasPrefix(file.Name, prefix)
+				resourcePath := strings.TrimPrefix(filepath.ToSlash(file.Name), updateName + "/")
+				foundInResources := opts.ResourceFiles.isResourceFile(resourcePath)
+				logger.Debug(fmt.Sprintf("foundInResources: %v", foundInResources))
+				if !hasPrefix && !foundInResources {
+					return nil, nil, newErrorWithCode(opValidate, CodeUnknownFile, nil, fmt.Sprintf("Unknown file found: '%s'.", file.Name))
+				}
+				if !hasPrefix {
+					resourceFilePaths = append(resourceFilePaths, file.Name)
+				}
+				logger.Debug(fmt.Sprintf("Trimming: %s using %s", file.Name, prefix + constant.PATH_SEPARATOR))
+				relativePath := strings.TrimPrefix(file.Name, prefix + constant.PATH_SEPARATOR)
+				fileMap[relativePath] = false
+			}
+		}
+	}
+	for _, directory := range unknownDirectories {
+		hasResourceFiles := false
+		for _, resourceFilePath := range resourceFilePaths {
+			if strings.HasPrefix(resourceFilePath, strings.TrimSuffix(directory, "/") + "/") {
+				hasResourceFiles = true
+				break
+			}
+		}
+		if !hasResourceFiles {
+			return nil, nil, newErrorWithCode(opValidate, CodeUnknownFile, nil, "Unknown directory found: '" + directory + "'")
+		}
+	}
+	checkNotAContributionFile(isASecPatch, isNotAContributionFileFound, result)
+	return fileMap, &updateDescriptor, nil
+}
+
+//This function will check whether the configuration files referenced in the given instructions exist in the
+// distribution or are added by the update. isInDistribution reports whether a file relative to carbon.home exists in
+// the distribution.
+func checkConfigFiles(op string, instructions *util.Instructions, updateDescriptor *util.UpdateDescriptor, isInDistribution func(string) bool) error {
+	missingFiles := make([]string, 0)
+	for _, configFile := range instructions.GetConfigFiles() {
+		if isInDistribution(configFile) || util.IsStringIsInSlice(configFile, updateDescriptor.File_changes.Added_files) {
+			continue
+		}
+		missingFiles = append(missingFiles, configFile)
+	}
+	logger.Debug(fmt.Sprintf("missingFiles: %v", missingFiles))
+	if len(missingFiles) > 0 {
+		return newErrorWithCode(op, CodeInstructionsInvalid, nil, fmt.Sprintf("Configuration files in the '%s' file not found in the distribution: %s",
+			constant.INSTRUCTIONS_YAML_FILE, strings.Join(missingFiles, ", ")))
+	}
+	return nil
+}
+
+//This function will add a warning if the NOT_A_CONTRIBUTION.txt is missing in a non security update or is available in
+// a security update.
+func checkNotAContributionFile(isASecPatch, isNotAContributionFileFound bool, result *Result) {
+	if !isASecPatch && !isNotAContributionFileFound {
+		result.addWarning("This update is not a security update. But '" + constant.NOT_A_CONTRIBUTION_FILE + "' was not found. Please review and add '" + constant.NOT_A_CONTRIBUTION_FILE + "' file if necessary.")
+	} else if isASecPatch && isNotAContributionFileFound {
+		result.addWarning("This update is a security update. But '" + constant.NOT_A_CONTRIBUTION_FILE + "' was found. Please review and remove '" + constant.NOT_A_CONTRIBUTION_FILE + "' file if necessary.")
+	}
+}
+
+//This function will unmarshal and validate the given update-descriptor.yaml content. Both version 1 and version 2
+// descriptors are supported. Version 2 descriptors are converted to the version 1 struct.
+func unmarshalUpdateDescriptor(data []byte, updateDescriptor *util.UpdateDescriptor) error {
+	descriptorVersion, err := util.GetDescriptorVersion(data)
+	if err != nil {
+		return err
+	}
+	logger.Debug(fmt.Sprintf("descriptorVersion: %s", descriptorVersion))
+	if descriptorVersion == constant.DESCRIPTOR_VERSION_2 {
+		updateDescriptorV2 := util.UpdateDescriptorV2{}
+		err = yaml.Unmarshal(data, &updateDescriptorV2)
+		if err != nil {
+			return err
+		}
+		err = util.ValidateUpdateDescriptorV2(&updateDescriptorV2)
+		if err != nil {
+			return err
+		}
+		*updateDescriptor = *util.ConvertToUpdateDescriptorV1(&updateDescriptorV2)
+		return nil
+	}
+	err = yaml.Unmarshal(data, updateDescriptor)
+	if err != nil {
+		return err
+	}
+	return util.ValidateUpdateDescriptor(updateDescriptor)
+}
+
+//This function will validate the provided file. If the word 'patch' is found, a warning is added to the result.
+func validateFile(file *zip.File, fileName, fullPath, updateName string, result *Result) ([]byte, error) {
+	logger.Debug(fmt.Sprintf("Validating '%s' at '%s' started.", fileName, fullPath))
+	data, err := readResourceFile(file, fileName, fullPath, updateName)
+	if err != nil {
+		return nil, err
+	}
+	checkFileContent(data, fileName, result)
+	logger.Debug(fmt.Sprintf("Validating '%s' finished.", fileName))
+	return data, nil
+}
+
+//This function will read the provided resource file after checking whether it is in the root directory of the update.
+func readResourceFile(file *zip.File, fileName, fullPath, updateName string) ([]byte, error) {
+	parent := strings.TrimSuffix(file.Name, getFileName(file.FileInfo().Name()))
+	if file.Name != fullPath {
+		return nil, newErrorWithCode(opValidate, CodeMisplacedResourceFile, nil, fmt.Sprintf("'%s' found at '%s'. It should be in the '%s' directory.", fileName, parent, updateName))
+	} else {
+		logger.Debug(fmt.Sprintf("'%s' found at '%s'.", fileName, parent))
+	}
+	zippedFile, err := file.Open()
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error occurred while opening the zip file: %v", err))
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(zippedFile)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Error occurred while reading the zip file: %v", err))
+		return nil, err
+	}
+	zippedFile.Close()
+	return data, nil
+}
+
+//This function will check the content of the given resource file. If the word 'patch' is found, a warning is added to
+// the result.
+func checkFileContent(data []byte, fileName string, result *Result) {
+	dataString := string(data)
+	dataString = util.ProcessString(dataString, "\n", true)
+
+	//check
+	regex := regexp.MustCompile(constant.PATCH_REGEX)
+	allMatches := regex.FindAllStringSubmatch(dataString, -1)
+	logger.Debug(fmt.Sprintf("All matches: %v", allMatches))
+	if len(allMatches) > 0 {
+		warning := fmt.Sprintf("'%v' file contains the word 'patch' in following lines. Please review and change it to 'update' if possible.", fileName)
+		for i, line := range allMatches {
+			warning += fmt.Sprintf("\nMatching Line #%d - %v", i + 1, line[0])
+		}
+		result.addWarning(warning)
+	}
+}
+
+//This function will check whether all placeholders are removed from the given update-descriptor.yaml content. If
+// placeholders are found, an error is returned unless placeholders are allowed. If they are allowed, a warning is added
+// for each placeholder instead.
+func checkPlaceholders(op string, data []byte, allowPlaceholders bool, result *Result) error {
+	placeholders, err := util.FindPlaceholders(data)
+	if err != nil {
+		return newErrorWithCode(op, CodeDescriptorInvalid, err, "'" + constant.UPDATE_DESCRIPTOR_FILE + "' is invalid.")
+	}
+	logger.Debug(fmt.Sprintf("placeholders: %v", placeholders))
+	if len(placeholders) == 0 {
+		return nil
+	}
+	if !allowPlaceholders {
+		return newErrorWithCode(op, CodePlaceholderFound, nil, fmt.Sprintf("Please add the correct values for the following placeholders in the '%s' file.\n%s",
+			constant.UPDATE_DESCRIPTOR_FILE, util.GetPlaceholdersMessage(placeholders)))
+	}
+	for _, placeholder := range placeholders {
+		result.addWarning(fmt.Sprintf("Please add the correct value for '%s' in the '%s' field at line %d of the '%s' file.",
+			placeholder.Placeholder, placeholder.Field, placeholder.Line, constant.UPDATE_DESCRIPTOR_FILE))
+	}
+	return nil
+}
+
+//This function reads the product distribution at the given location.
+func readDistributionZip(ctx *runContext, filename string) (map[string]bool, error) {
+	fileMap := make(map[string]bool)
+	// Create a reader out of the zip archive
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	productName := ctx.productName
+	logger.Debug(fmt.Sprintf("productName: %s", productName))
+	// Iterate through each file/dir found in
+	for _, file := range zipReader.Reader.File {
+		logger.Trace(file.Name)
+		relativePath := strings.TrimPrefix(file.Name, productName + "/")
+		if !file.FileInfo().IsDir() {
+			fileMap[relativePath] = false
+		}
+	}
+	return fileMap, nil
+}
+
+//This function will return the product name using the location of the distribution. Ex: wso2esb-4.9.0
+func getProductName(distributionPath string) string {
+	lastIndex := strings.LastIndex(distributionPath, constant.PATH_SEPARATOR)
+	productName := strings.TrimSuffix(distributionPath[lastIndex + 1:], ".zip")
+	logger.Debug(fmt.Sprintf("productName: %s", productName))
+	return productName
+}
+
+//When reading zip files in windows, file.FileInfo().Name() does not return the filename correctly
+// (where file *zip.File) To fix this issue, this function was added.
+func getFileName(filename string) string {
+	filename = filepath.ToSlash(filename)
+	if lastIndex := strings.LastIndex(filename, "/"); lastIndex > -1 {
+		filename = filename[lastIndex + 1:]
+	}
+	return filename
+}
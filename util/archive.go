@@ -0,0 +1,150 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/wum-uc/constant"
+)
+
+// This function will return the timestamp which should be used for all entries in a zip file. If the
+// SOURCE_DATE_EPOCH environment variable is set, it will be used. Otherwise a fixed timestamp is returned.
+func GetZipTimestamp() (time.Time, error) {
+	sourceDateEpoch := os.Getenv(constant.SOURCE_DATE_EPOCH)
+	if len(sourceDateEpoch) == 0 {
+		return time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("'%s' is not a valid value for %s. It should be a unix timestamp.",
+			sourceDateEpoch, constant.SOURCE_DATE_EPOCH))
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// This function will create a zip file at the given location using the given directory. The directory will be the root
+// directory of the zip file. Entries are added in sorted order with forward slash names, the given timestamp and
+// normalized permissions so that the same directory will always produce the same zip file.
+func ZipDirectory(zipPath, directory string, timestamp time.Time) error {
+	parent := filepath.Dir(directory)
+	// Collect all entries first so that they can be added in sorted order
+	entries := make(map[string]os.FileInfo)
+	err := filepath.Walk(directory, func(absolutePath string, fileInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relativePath, err := filepath.Rel(parent, absolutePath)
+		if err != nil {
+			return err
+		}
+		entries[filepath.ToSlash(relativePath)] = fileInfo
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0)
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	err = writeZipEntries(zipFile, parent, names, entries, timestamp)
+	// Errors of Close are checked because buffered data is written when the file is closed. Otherwise a truncated
+	// zip file would be reported as created.
+	if closeErr := zipFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(zipPath)
+		return err
+	}
+	return nil
+}
+
+// This function will write the given entries to the given zip file in the given order.
+func writeZipEntries(zipFile io.Writer, parent string, names []string, entries map[string]os.FileInfo, timestamp time.Time) error {
+	zipWriter := zip.NewWriter(zipFile)
+	for _, name := range names {
+		fileInfo := entries[name]
+		header := &zip.FileHeader{
+			Name: name,
+		}
+		header.SetModTime(timestamp)
+		if fileInfo.IsDir() {
+			header.Name = name + "/"
+			header.SetMode(os.ModeDir | 0755)
+		} else {
+			header.Method = zip.Deflate
+			header.SetMode(0644)
+		}
+		logger.Trace(fmt.Sprintf("[ZIP] %s", header.Name))
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if fileInfo.IsDir() {
+			continue
+		}
+		err = copyToWriter(writer, filepath.Join(parent, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+// This function will copy the content of the file at the given location to the given writer.
+func copyToWriter(writer io.Writer, location string) error {
+	file, err := os.Open(location)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(writer, file)
+	return err
+}
+
+
+// This function will read the given files in the zip file at the given location. Paths should use / as the separator.
+// The returned map contains only the files which are found in the zip file.
+func ReadFilesFromZip(zipPath string, filePaths []string) (map[string][]byte, error) {
+	zipReader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	filesMap := make(map[string][]byte)
+	for _, file := range zipReader.Reader.File {
+		filePath := filepath.ToSlash(file.Name)
+		if !IsStringIsInSlice(filePath, filePaths) || file.FileInfo().IsDir() {
+			continue
+		}
+		zippedFile, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(zippedFile)
+		zippedFile.Close()
+		if err != nil {
+			return nil, err
+		}
+		filesMap[filePath] = data
+	}
+	return filesMap, nil
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestZipDirectory(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	directory := filepath.Join(root, "WSO2-CARBON-UPDATE-4.4.0-0001")
+	err = CreateDirectory(filepath.Join(directory, "carbon.home", "lib"))
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(directory, "carbon.home", "lib", "b.jar"), []byte("b"), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	err = ioutil.WriteFile(filepath.Join(directory, "LICENSE.txt"), []byte("license"), 0755)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	timestamp, err := GetZipTimestamp()
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	firstZip := filepath.Join(root, "first.zip")
+	err = ZipDirectory(firstZip, directory, timestamp)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	// Change the modification time of a file and create the zip again
+	err = os.Chtimes(filepath.Join(directory, "LICENSE.txt"), time.Now(), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	secondZip := filepath.Join(root, "second.zip")
+	err = ZipDirectory(secondZip, directory, timestamp)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	firstData, _ := ioutil.ReadFile(firstZip)
+	secondData, _ := ioutil.ReadFile(secondZip)
+	if !bytes.Equal(firstData, secondData) {
+		t.Error("Test failed. Zip files are not identical")
+	}
+
+	zipReader, err := zip.OpenReader(firstZip)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer zipReader.Close()
+	expected := []string{
+		"WSO2-CARBON-UPDATE-4.4.0-0001/",
+		"WSO2-CARBON-UPDATE-4.4.0-0001/LICENSE.txt",
+		"WSO2-CARBON-UPDATE-4.4.0-0001/carbon.home/",
+		"WSO2-CARBON-UPDATE-4.4.0-0001/carbon.home/lib/",
+		"WSO2-CARBON-UPDATE-4.4.0-0001/carbon.home/lib/b.jar",
+	}
+	if len(zipReader.File) != len(expected) {
+		t.Fatalf("Test failed, expected %d entries, actual: %d", len(expected), len(zipReader.File))
+	}
+	for i, file := range zipReader.File {
+		if file.Name != expected[i] {
+			t.Errorf("Test failed, expected: %s, actual: %s", expected[i], file.Name)
+		}
+	}
+}
+
+func TestZipDirectoryFailure(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	directory := filepath.Join(root, "WSO2-CARBON-UPDATE-4.4.0-0001")
+	err = CreateDirectory(directory)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	// A file which cannot be read makes the zip incomplete
+	err = os.Symlink(filepath.Join(root, "missing.txt"), filepath.Join(directory, "LICENSE.txt"))
+	if err != nil {
+		t.Skipf("Symbolic links are not supported. %v", err)
+	}
+
+	zipPath := filepath.Join(root, "update.zip")
+	err = ZipDirectory(zipPath, directory, time.Unix(0, 0))
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Error("Test failed. Incomplete zip file should be removed")
+	}
+}
+
+func TestGetZipTimestamp(t *testing.T) {
+	os.Setenv("SOURCE_DATE_EPOCH", "1500000000")
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	timestamp, err := GetZipTimestamp()
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+	if timestamp.Unix() != 1500000000 {
+		t.Errorf("Test failed, expected: %d, actual: %d", 1500000000, timestamp.Unix())
+	}
+
+	os.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	_, err = GetZipTimestamp()
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+}
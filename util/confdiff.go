@@ -0,0 +1,302 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	yamlv2 "gopkg.in/yaml.v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats of the configuration files which can be diffed
+const (
+	ConfFormatXml = "xml"
+	ConfFormatProperties = "properties"
+	ConfFormatYaml = "yaml"
+)
+
+// Operations of a configuration change
+const (
+	ConfOpAdd = "add"
+	ConfOpSet = "set"
+	ConfOpRemove = "remove"
+)
+
+// struct which is used to store the structured diffs of the configuration files changed by an update
+type ConfDiffs struct {
+	Files []ConfDiff `yaml:"files"`
+}
+
+// struct which is used to store the changes of a single configuration file. File is relative to carbon.home.
+type ConfDiff struct {
+	File    string       `yaml:"file"`
+	Format  string       `yaml:"format"`
+	Changes []ConfChange `yaml:"changes"`
+}
+
+// struct which is used to store a change of a single value in a configuration file. Path is the key of a properties
+// file, or the path of an element or an attribute (Ex: /Server/Ports/Offset, /Server/@version) in XML and YAML files.
+// Repeated elements and list items are indexed from 1. Ex: /Server/Users/User[2]. Old is the value in the distribution
+// and Value is the value in the update.
+type ConfChange struct {
+	Op    string `yaml:"op"`
+	Path  string `yaml:"path"`
+	Old   string `yaml:"old,omitempty"`
+	Value string `yaml:"value,omitempty"`
+}
+
+// This function will return the format of the given configuration file. An empty string is returned if the file is not
+// in a conf directory or the format is not supported.
+func GetConfFormat(filePath string) string {
+	filePath = strings.Replace(filePath, "\\", "/", -1)
+	if !IsStringIsInSlice("conf", strings.Split(path.Dir(filePath), "/")) {
+		return ""
+	}
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".xml":
+		return ConfFormatXml
+	case ".properties":
+		return ConfFormatProperties
+	case ".yaml", ".yml":
+		return ConfFormatYaml
+	}
+	return ""
+}
+
+// This function will return the changes which should be applied to the original configuration file to get the values
+// of the updated file. Comments and formatting are not considered as changes.
+func DiffConf(format string, original, updated []byte) ([]ConfChange, error) {
+	originalValues, originalPaths, err := flattenConf(format, original)
+	if err != nil {
+		return nil, err
+	}
+	updatedValues, updatedPaths, err := flattenConf(format, updated)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]ConfChange, 0)
+	for _, valuePath := range updatedPaths {
+		oldValue, found := originalValues[valuePath]
+		if !found {
+			changes = append(changes, ConfChange{Op: ConfOpAdd, Path: valuePath, Value: updatedValues[valuePath]})
+		} else if oldValue != updatedValues[valuePath] {
+			changes = append(changes, ConfChange{Op: ConfOpSet, Path: valuePath, Old: oldValue, Value: updatedValues[valuePath]})
+		}
+	}
+	for _, valuePath := range originalPaths {
+		if _, found := updatedValues[valuePath]; !found {
+			changes = append(changes, ConfChange{Op: ConfOpRemove, Path: valuePath, Old: originalValues[valuePath]})
+		}
+	}
+	return changes, nil
+}
+
+// This function will check whether the given changes can be applied to the given configuration file. Values which are
+// set or removed should have the old value and values which are added should not exist. A description of each
+// conflict is returned.
+func CheckConfChanges(format string, data []byte, changes []ConfChange) ([]string, error) {
+	values, _, err := flattenConf(format, data)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := make([]string, 0)
+	for _, change := range changes {
+		currentValue, found := values[change.Path]
+		switch change.Op {
+		case ConfOpAdd:
+			if found {
+				conflicts = append(conflicts, fmt.Sprintf("'%s' already exists with the value '%s'.", change.Path, currentValue))
+			}
+		default:
+			if !found {
+				conflicts = append(conflicts, fmt.Sprintf("'%s' not found.", change.Path))
+			} else if currentValue != change.Old {
+				conflicts = append(conflicts, fmt.Sprintf("'%s' should be '%s' but found '%s'.", change.Path, change.Old,
+					currentValue))
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+// This function will unmarshal and validate the given conf-diffs.yaml content.
+func UnmarshalConfDiffs(data []byte) (*ConfDiffs, error) {
+	confDiffs := ConfDiffs{}
+	err := yamlv2.UnmarshalStrict(data, &confDiffs)
+	if err != nil {
+		return nil, err
+	}
+	for _, confDiff := range confDiffs.Files {
+		if len(confDiff.File) == 0 {
+			return nil, errors.New("'file' of an entry in 'files' is empty.")
+		}
+		if confDiff.Format != ConfFormatXml && confDiff.Format != ConfFormatProperties && confDiff.Format != ConfFormatYaml {
+			return nil, errors.New(fmt.Sprintf("Format '%s' of '%s' is not supported.", confDiff.Format, confDiff.File))
+		}
+		for _, change := range confDiff.Changes {
+			if change.Op != ConfOpAdd && change.Op != ConfOpSet && change.Op != ConfOpRemove {
+				return nil, errors.New(fmt.Sprintf("Operation '%s' of '%s' in '%s' is not supported.", change.Op,
+					change.Path, confDiff.File))
+			}
+			if len(change.Path) == 0 {
+				return nil, errors.New(fmt.Sprintf("'path' of a change in '%s' is empty.", confDiff.File))
+			}
+		}
+	}
+	return &confDiffs, nil
+}
+
+// This function will return the values of the given configuration file using their paths as keys. Paths are returned in
+// the order they appear in the file as well.
+func flattenConf(format string, data []byte) (map[string]string, []string, error) {
+	values := make(map[string]string)
+	paths := make([]string, 0)
+	addValue := func(valuePath, value string) {
+		if _, found := values[valuePath]; !found {
+			paths = append(paths, valuePath)
+		}
+		values[valuePath] = value
+	}
+	var err error
+	switch format {
+	case ConfFormatXml:
+		err = flattenXml(data, addValue)
+	case ConfFormatProperties:
+		err = flattenProperties(data, addValue)
+	case ConfFormatYaml:
+		err = flattenYaml(data, addValue)
+	default:
+		err = errors.New(fmt.Sprintf("Format '%s' is not supported.", format))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, paths, nil
+}
+
+// This function will read the keys and the values of a properties file. Lines ending with \ are joined with the next
+// line.
+func flattenProperties(data []byte, addValue func(string, string)) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	line := ""
+	for scanner.Scan() {
+		line += strings.TrimLeft(scanner.Text(), " \t")
+		if strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\") {
+			line = strings.TrimSuffix(line, "\\")
+			continue
+		}
+		if len(line) > 0 && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "!") {
+			separatorIndex := strings.IndexAny(line, "=: \t")
+			if separatorIndex < 0 {
+				addValue(line, "")
+			} else {
+				value := strings.TrimLeft(line[separatorIndex + 1:], " \t")
+				if line[separatorIndex] == ' ' || line[separatorIndex] == '\t' {
+					value = strings.TrimLeft(strings.TrimPrefix(strings.TrimPrefix(value, "="), ":"), " \t")
+				}
+				addValue(strings.TrimSpace(line[:separatorIndex]), strings.TrimRight(value, " \t"))
+			}
+		}
+		line = ""
+	}
+	return scanner.Err()
+}
+
+// This function will read the values of a YAML file. Mappings and lists are read recursively and only the scalar
+// values are returned.
+func flattenYaml(data []byte, addValue func(string, string)) error {
+	document := yaml.Node{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return err
+	}
+	var walk func(valuePath string, valueNode *yaml.Node)
+	walk = func(valuePath string, valueNode *yaml.Node) {
+		switch valueNode.Kind {
+		case yaml.DocumentNode:
+			for _, childNode := range valueNode.Content {
+				walk(valuePath, childNode)
+			}
+		case yaml.MappingNode:
+			for i := 0; i + 1 < len(valueNode.Content); i += 2 {
+				walk(valuePath + "/" + valueNode.Content[i].Value, valueNode.Content[i + 1])
+			}
+		case yaml.SequenceNode:
+			for i, childNode := range valueNode.Content {
+				walk(fmt.Sprintf("%s[%d]", valuePath, i + 1), childNode)
+			}
+		case yaml.AliasNode:
+			walk(valuePath, valueNode.Alias)
+		case yaml.ScalarNode:
+			addValue(valuePath, valueNode.Value)
+		}
+	}
+	walk("", &document)
+	return nil
+}
+
+// struct which is used to store the state of an element while reading an XML file
+type xmlElement struct {
+	path        string
+	childCounts map[string]int
+	hasChildren bool
+	text        string
+}
+
+// This function will read the text of the elements which do not have child elements and the attributes of all
+// elements of an XML file.
+func flattenXml(data []byte, addValue func(string, string)) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	// Configuration files can have any encoding in the declaration. Values are compared as they are.
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	elements := []*xmlElement{{childCounts: make(map[string]int)}}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			parent := elements[len(elements) - 1]
+			parent.hasChildren = true
+			parent.childCounts[token.Name.Local]++
+			elementPath := parent.path + "/" + token.Name.Local
+			if count := parent.childCounts[token.Name.Local]; count > 1 {
+				elementPath = fmt.Sprintf("%s[%d]", elementPath, count)
+			}
+			for _, attr := range token.Attr {
+				attrName := attr.Name.Local
+				if attr.Name.Space == "xmlns" {
+					attrName = "xmlns:" + attrName
+				}
+				addValue(elementPath + "/@" + attrName, attr.Value)
+			}
+			elements = append(elements, &xmlElement{path: elementPath, childCounts: make(map[string]int)})
+		case xml.CharData:
+			elements[len(elements) - 1].text += string(token)
+		case xml.EndElement:
+			element := elements[len(elements) - 1]
+			elements = elements[:len(elements) - 1]
+			if !element.hasChildren {
+				addValue(element.path, strings.TrimSpace(element.text))
+			}
+		}
+	}
+	if len(elements) != 1 {
+		return errors.New("XML file is incomplete.")
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetConfFormat(t *testing.T) {
+	testCases := []struct {
+		filePath string
+		expected string
+	}{
+		{"repository/conf/carbon.xml", ConfFormatXml},
+		{"repository/conf/axis2/axis2.xml", ConfFormatXml},
+		{"repository/conf/log4j.properties", ConfFormatProperties},
+		{"conf/deployment.yml", ConfFormatYaml},
+		{"repository/components/plugins/a.jar", ""},
+		{"repository/deployment/server/carbon.xml", ""},
+	}
+	for _, testCase := range testCases {
+		actual := GetConfFormat(testCase.filePath)
+		if actual != testCase.expected {
+			t.Errorf("Test failed, expected: %s, actual: %s", testCase.expected, actual)
+		}
+	}
+}
+
+func TestDiffConf(t *testing.T) {
+	testCases := []struct {
+		format   string
+		original string
+		updated  string
+		expected []ConfChange
+	}{
+		{
+			ConfFormatXml,
+			`<?xml version="1.0" encoding="ISO-8859-1"?>
+<Server xmlns="http://wso2.org/projects/carbon/carbon.xml">
+    <!-- Host name -->
+    <HostName>localhost</HostName>
+    <Ports><Offset>0</Offset></Ports>
+    <User>admin</User>
+    <User>guest</User>
+</Server>`,
+			`<?xml version="1.0" encoding="ISO-8859-1"?>
+<Server xmlns="http://wso2.org/projects/carbon/carbon.xml" version="2">
+    <HostName>localhost</HostName>
+    <Ports>
+        <Offset>1</Offset>
+    </Ports>
+    <User>admin</User>
+</Server>`,
+			[]ConfChange{
+				{Op: ConfOpAdd, Path: "/Server/@version", Value: "2"},
+				{Op: ConfOpSet, Path: "/Server/Ports/Offset", Old: "0", Value: "1"},
+				{Op: ConfOpRemove, Path: "/Server/User[2]", Old: "guest"},
+			},
+		},
+		{
+			ConfFormatProperties,
+			"# Root logger\nlog4j.rootLogger=INFO, CARBON_CONSOLE\nlog4j.logger.a = DEBUG\nlog4j.logger.b:WARN\n",
+			"log4j.rootLogger=INFO, \\\n    CARBON_CONSOLE\nlog4j.logger.a DEBUG\nlog4j.logger.c=ERROR\n",
+			[]ConfChange{
+				{Op: ConfOpAdd, Path: "log4j.logger.c", Value: "ERROR"},
+				{Op: ConfOpRemove, Path: "log4j.logger.b", Old: "WARN"},
+			},
+		},
+		{
+			ConfFormatYaml,
+			"server:\n  hostname: localhost\n  offset: 0\nusers:\n- admin\n",
+			"# Server\nserver:\n  hostname: localhost\n  offset: 1\nusers:\n- admin\n- guest\n",
+			[]ConfChange{
+				{Op: ConfOpSet, Path: "/server/offset", Old: "0", Value: "1"},
+				{Op: ConfOpAdd, Path: "/users[2]", Value: "guest"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		changes, err := DiffConf(testCase.format, []byte(testCase.original), []byte(testCase.updated))
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if !reflect.DeepEqual(changes, testCase.expected) {
+			t.Errorf("Test failed, expected: %v, actual: %v", testCase.expected, changes)
+		}
+
+		conflicts, err := CheckConfChanges(testCase.format, []byte(testCase.original), changes)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if len(conflicts) != 0 {
+			t.Errorf("Test failed. Unexpected conflicts: %v", conflicts)
+		}
+		conflicts, err = CheckConfChanges(testCase.format, []byte(testCase.updated), changes)
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if len(conflicts) != len(changes) {
+			t.Errorf("Test failed, expected: %d, actual: %v", len(changes), conflicts)
+		}
+	}
+}
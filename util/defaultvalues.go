@@ -11,12 +11,18 @@ var (
 	// CheckMd5Disabled to false here.
 	CheckMd5Disabled = false
 	ResourceFiles_Mandatory = []string{"update-descriptor.yaml", "LICENSE.txt"}
-	ResourceFiles_Optional = []string{"instructions.txt", "NOT_A_CONTRIBUTION.txt"}
+	ResourceFiles_Optional = []string{"instructions.txt", "instructions.yaml", "NOT_A_CONTRIBUTION.txt"}
 	ResourceFiles_Skip = []string{"README.txt"}
+	// Files which are ignored in update directories and update zips in addition to the patterns in the .wumucignore
+	IgnorePatterns_Default = []string{".DS_Store", "Thumbs.db", "desktop.ini", "*.swp", "*.swo", "*~", ".#*",
+		".git/", ".svn/", ".idea/", ".vscode/", ".wumucignore"}
 	PlatformVersions = map[string]string{
 		"4.2.0": "turing",
 		"4.3.0": "perlis",
 		"4.4.0": "wilkes",
 		"5.0.0": "hamming",
 	}
+	// Product catalog is empty by default. Products in the applies_to field are validated only if a catalog is
+	// configured.
+	ProductCatalog = map[string]map[string]string{}
 )
@@ -0,0 +1,374 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/wum-uc/constant"
+	"gopkg.in/yaml.v2"
+)
+
+// struct which is used to read version 2 update-descriptor.yaml files
+type UpdateDescriptorV2 struct {
+	DescriptorVersion string            `yaml:"descriptor_version"`
+	UpdateNumber      string            `yaml:"update_number"`
+	PlatformVersion   string            `yaml:"platform_version"`
+	PlatformName      string            `yaml:"platform_name"`
+	AppliesTo         []string          `yaml:"applies_to"`
+	SecuritySeverity  string            `yaml:"security_severity,omitempty"`
+	Requires          []string          `yaml:"requires,omitempty"`
+	Supersedes        []string          `yaml:"supersedes,omitempty"`
+	BugFixes          map[string]string `yaml:"bug_fixes"`
+	Description       string            `yaml:"description"`
+	FileChanges       FileChangesV2     `yaml:"file_changes"`
+}
+
+// struct which is used to read the file_changes section of version 2 update-descriptor.yaml files
+type FileChangesV2 struct {
+	AddedFiles    []string `yaml:"added_files"`
+	RemovedFiles  []string `yaml:"removed_files"`
+	ModifiedFiles []string `yaml:"modified_files"`
+}
+
+// This is used to read only the descriptor_version field of the update-descriptor.yaml
+type descriptorVersionHolder struct {
+	DescriptorVersion string `yaml:"descriptor_version"`
+}
+
+// This function will return the descriptor version of the given update-descriptor.yaml content. If the
+// 'descriptor_version' field is not found, the descriptor is considered as a version 1 descriptor.
+func GetDescriptorVersion(data []byte) (string, error) {
+	holder := descriptorVersionHolder{}
+	err := yaml.Unmarshal(data, &holder)
+	if err != nil {
+		return "", err
+	}
+	switch holder.DescriptorVersion {
+	case "", constant.DESCRIPTOR_VERSION_1:
+		return constant.DESCRIPTOR_VERSION_1, nil
+	case constant.DESCRIPTOR_VERSION_2:
+		return constant.DESCRIPTOR_VERSION_2, nil
+	default:
+		return "", errors.New(fmt.Sprintf("'descriptor_version' %s is not supported. Supported versions are %s and %s.",
+			holder.DescriptorVersion, constant.DESCRIPTOR_VERSION_1, constant.DESCRIPTOR_VERSION_2))
+	}
+}
+
+// This function will read the update-descriptor.yaml in the given directory and return its descriptor version.
+func GetDescriptorVersionOfFile(filename, updateDirectoryPath string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(updateDirectoryPath, filename))
+	if err != nil {
+		return "", err
+	}
+	return GetDescriptorVersion(data)
+}
+
+// This function will read a version 2 update-descriptor.yaml
+func LoadUpdateDescriptorV2(filename, updateDirectoryPath string) (*UpdateDescriptorV2, error) {
+	//Construct the file path
+	updateDescriptorPath := filepath.Join(updateDirectoryPath, filename)
+	logger.Debug(fmt.Sprintf("updateDescriptorPath: %s", updateDescriptorPath))
+
+	//Read the file
+	yamlFile, err := ioutil.ReadFile(updateDescriptorPath)
+	if err != nil {
+		return nil, err
+	}
+	updateDescriptor := UpdateDescriptorV2{}
+	//Un-marshal the update-descriptor file to updateDescriptor struct
+	err = yaml.Unmarshal(yamlFile, &updateDescriptor)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("updateDescriptor: %v", updateDescriptor))
+	return &updateDescriptor, nil
+}
+
+// This function will validate a version 2 update-descriptor.yaml
+func ValidateUpdateDescriptorV2(updateDescriptor *UpdateDescriptorV2) error {
+	if updateDescriptor.DescriptorVersion != constant.DESCRIPTOR_VERSION_2 {
+		return errors.New(fmt.Sprintf("'descriptor_version' is not valid. It should be %s.", constant.DESCRIPTOR_VERSION_2))
+	}
+	if err := ValidateUpdateNumber(updateDescriptor.UpdateNumber); err != nil {
+		return err
+	}
+	if err := ValidatePlatformVersion(updateDescriptor.PlatformVersion); err != nil {
+		return err
+	}
+	if err := ValidatePlatformName(updateDescriptor.PlatformName); err != nil {
+		return err
+	}
+	if len(updateDescriptor.AppliesTo) == 0 {
+		return errors.New("'applies_to' field not found.")
+	}
+	for _, product := range updateDescriptor.AppliesTo {
+		if len(strings.TrimSpace(product)) == 0 {
+			return errors.New("'applies_to' field contains an empty value.")
+		}
+	}
+	if err := ValidateSecuritySeverity(updateDescriptor.SecuritySeverity); err != nil {
+		return err
+	}
+	if err := ValidateBugFixes(updateDescriptor.BugFixes); err != nil {
+		return err
+	}
+	if err := ValidateDescription(updateDescriptor.Description); err != nil {
+		return err
+	}
+	if err := ValidateUpdateDependencies(updateDescriptor.UpdateNumber, updateDescriptor.Requires,
+		updateDescriptor.Supersedes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// This function will validate the requires and supersedes fields of the update-descriptor.yaml. These fields are
+// optional. All values should be valid update numbers and the update should not reference itself.
+func ValidateUpdateDependencies(updateNumber string, requires, supersedes []string) error {
+	if err := validateUpdateNumberList("requires", updateNumber, requires); err != nil {
+		return err
+	}
+	if err := validateUpdateNumberList("supersedes", updateNumber, supersedes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// This function will validate a list of update numbers in the given field.
+func validateUpdateNumberList(fieldName, updateNumber string, updateNumbers []string) error {
+	for _, value := range updateNumbers {
+		matches, err := regexp.MatchString(constant.UPDATE_NUMBER_REGEX, value)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			return errors.New(fmt.Sprintf("'%s' contains an invalid update number '%s'. It should match '%s'.",
+				fieldName, value, constant.UPDATE_NUMBER_REGEX))
+		}
+		if value == updateNumber {
+			return errors.New(fmt.Sprintf("'%s' contains the update number of the update itself '%s'.",
+				fieldName, value))
+		}
+	}
+	return nil
+}
+
+// This function will find a cycle which contains the given update in the given dependency graph. Keys of the graph are
+// update numbers and values are the update numbers which the update requires or supersedes. If a cycle is found, the
+// update numbers in the cycle are returned starting and ending with the given update number. Otherwise nil is returned.
+func FindDependencyCycle(updateNumber string, dependencyGraph map[string][]string) []string {
+	visited := make(map[string]bool)
+	var findPath func(current string, path []string) []string
+	findPath = func(current string, path []string) []string {
+		for _, dependency := range dependencyGraph[current] {
+			if dependency == updateNumber {
+				return append(path, dependency)
+			}
+			if visited[dependency] {
+				continue
+			}
+			visited[dependency] = true
+			if cycle := findPath(dependency, append(path, dependency)); cycle != nil {
+				return cycle
+			}
+		}
+		return nil
+	}
+	return findPath(updateNumber, []string{updateNumber})
+}
+
+// This function will validate the security_severity field of the update-descriptor.yaml. This field is optional.
+func ValidateSecuritySeverity(securitySeverity string) error {
+	switch securitySeverity {
+	case "", constant.SECURITY_SEVERITY_CRITICAL, constant.SECURITY_SEVERITY_HIGH, constant.SECURITY_SEVERITY_MEDIUM,
+		constant.SECURITY_SEVERITY_LOW:
+		return nil
+	}
+	return errors.New(fmt.Sprintf("'security_severity' is not valid. It should be one of %s, %s, %s or %s.",
+		constant.SECURITY_SEVERITY_CRITICAL, constant.SECURITY_SEVERITY_HIGH, constant.SECURITY_SEVERITY_MEDIUM,
+		constant.SECURITY_SEVERITY_LOW))
+}
+
+// This function will convert a version 1 update descriptor to a version 2 update descriptor. Comma separated products
+// in the applies_to field are converted to a list.
+func MigrateUpdateDescriptor(updateDescriptor *UpdateDescriptor) *UpdateDescriptorV2 {
+	appliesTo := GetProductsInAppliesTo(updateDescriptor.Applies_to)
+	return &UpdateDescriptorV2{
+		DescriptorVersion: constant.DESCRIPTOR_VERSION_2,
+		UpdateNumber: updateDescriptor.Update_number,
+		PlatformVersion: updateDescriptor.Platform_version,
+		PlatformName: updateDescriptor.Platform_name,
+		AppliesTo: appliesTo,
+		Requires: updateDescriptor.Requires,
+		Supersedes: updateDescriptor.Supersedes,
+		BugFixes: updateDescriptor.Bug_fixes,
+		Description: updateDescriptor.Description,
+		FileChanges: FileChangesV2{
+			AddedFiles: updateDescriptor.File_changes.Added_files,
+			RemovedFiles: updateDescriptor.File_changes.Removed_files,
+			ModifiedFiles: updateDescriptor.File_changes.Modified_files,
+		},
+	}
+}
+
+// This function will return the comma separated products in the given applies_to value.
+func GetProductsInAppliesTo(appliesTo string) []string {
+	products := make([]string, 0)
+	for _, product := range strings.Split(appliesTo, ",") {
+		product = strings.TrimSpace(product)
+		if len(product) > 0 {
+			products = append(products, product)
+		}
+	}
+	return products
+}
+
+// This function will check whether all products in the given applies_to value are in the given product catalog and
+// are based on the given platform version. Products should be in the '<product_name>-<product_version>' format. Keys of
+// the product catalog are product names and values are maps of product versions to platform versions.
+func ValidateProducts(appliesTo, platformVersion string, productCatalog map[string]map[string]string) error {
+	for _, product := range GetProductsInAppliesTo(appliesTo) {
+		index := strings.LastIndex(product, "-")
+		if index < 1 {
+			return errors.New(fmt.Sprintf("'applies_to' contains '%s' which is not in the "+
+				"'<product_name>-<product_version>' format.", product))
+		}
+		productName := strings.ToLower(product[:index])
+		productVersion := product[index + 1:]
+		versions, found := productCatalog[productName]
+		if !found {
+			return errors.New(fmt.Sprintf("'applies_to' contains an unknown product '%s'.", productName))
+		}
+		productPlatformVersion, found := versions[productVersion]
+		if !found {
+			return errors.New(fmt.Sprintf("'applies_to' contains an unknown version '%s' of '%s'.", productVersion,
+				productName))
+		}
+		if productPlatformVersion != platformVersion {
+			return errors.New(fmt.Sprintf("'%s' is based on platform version '%s'. But 'platform_version' is '%s'.",
+				product, productPlatformVersion, platformVersion))
+		}
+	}
+	return nil
+}
+
+// This function will return all products in the given product catalog which are based on the given platform version.
+// Products are returned in the '<product_name>-<product_version>' format in sorted order.
+func GetProductsOfPlatformVersion(platformVersion string, productCatalog map[string]map[string]string) []string {
+	products := make([]string, 0)
+	for productName, versions := range productCatalog {
+		for productVersion, productPlatformVersion := range versions {
+			if productPlatformVersion == platformVersion {
+				products = append(products, productName + "-" + productVersion)
+			}
+		}
+	}
+	sort.Strings(products)
+	return products
+}
+
+// This function will convert a version 2 update descriptor to a version 1 update descriptor. This is used to process
+// both versions in the same way when creating and validating updates. Fields which are only available in version 2
+// such as security_severity are not converted.
+func ConvertToUpdateDescriptorV1(updateDescriptor *UpdateDescriptorV2) *UpdateDescriptor {
+	updateDescriptorV1 := UpdateDescriptor{
+		Update_number: updateDescriptor.UpdateNumber,
+		Platform_version: updateDescriptor.PlatformVersion,
+		Platform_name: updateDescriptor.PlatformName,
+		Applies_to: strings.Join(updateDescriptor.AppliesTo, ", "),
+		Bug_fixes: updateDescriptor.BugFixes,
+		Description: updateDescriptor.Description,
+		Requires: updateDescriptor.Requires,
+		Supersedes: updateDescriptor.Supersedes,
+	}
+	updateDescriptorV1.File_changes.Added_files = updateDescriptor.FileChanges.AddedFiles
+	updateDescriptorV1.File_changes.Removed_files = updateDescriptor.FileChanges.RemovedFiles
+	updateDescriptorV1.File_changes.Modified_files = updateDescriptor.FileChanges.ModifiedFiles
+	return &updateDescriptorV1
+}
+
+// This function will set the file changes of the given version 1 update descriptor to the version 2 update descriptor.
+func SetFileChangesV2(updateDescriptor *UpdateDescriptorV2, updateDescriptorV1 *UpdateDescriptor) {
+	updateDescriptor.FileChanges.AddedFiles = updateDescriptorV1.File_changes.Added_files
+	updateDescriptor.FileChanges.RemovedFiles = updateDescriptorV1.File_changes.Removed_files
+	updateDescriptor.FileChanges.ModifiedFiles = updateDescriptorV1.File_changes.Modified_files
+}
+
+// This function will unmarshal the given update-descriptor.yaml content of any supported version. Version 2
+// descriptors are converted to the version 1 struct. The descriptor is not validated.
+func UnmarshalAnyUpdateDescriptor(data []byte) (*UpdateDescriptor, error) {
+	descriptorVersion, err := GetDescriptorVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if descriptorVersion == constant.DESCRIPTOR_VERSION_2 {
+		updateDescriptorV2 := UpdateDescriptorV2{}
+		err = yaml.Unmarshal(data, &updateDescriptorV2)
+		if err != nil {
+			return nil, err
+		}
+		return ConvertToUpdateDescriptorV1(&updateDescriptorV2), nil
+	}
+	updateDescriptor := UpdateDescriptor{}
+	err = yaml.Unmarshal(data, &updateDescriptor)
+	if err != nil {
+		return nil, err
+	}
+	return &updateDescriptor, nil
+}
+
+// This function will read the update-descriptor.yaml in the root directory of the given update zip. The root directory
+// should have the same name as the zip file.
+func ReadUpdateDescriptorFromZip(updateZipPath string) (*UpdateDescriptor, error) {
+	data, found, err := ReadFileFromUpdateZip(updateZipPath, constant.UPDATE_DESCRIPTOR_FILE)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		updateName := strings.TrimSuffix(filepath.Base(updateZipPath), ".zip")
+		return nil, errors.New(fmt.Sprintf("'%s' not found in '%s'.", path.Join(updateName, constant.UPDATE_DESCRIPTOR_FILE),
+			updateZipPath))
+	}
+	return UnmarshalAnyUpdateDescriptor(data)
+}
+
+// This function will read the given file in the root directory of the given update zip. The root directory should have
+// the same name as the zip file. The returned bool is false if the file is not found in the zip.
+func ReadFileFromUpdateZip(updateZipPath, filename string) ([]byte, bool, error) {
+	updateName := strings.TrimSuffix(filepath.Base(updateZipPath), ".zip")
+	filePath := path.Join(updateName, filename)
+	filesMap, err := ReadFilesFromZip(updateZipPath, []string{filePath})
+	if err != nil {
+		return nil, false, err
+	}
+	data, found := filesMap[filePath]
+	return data, found, nil
+}
+
+// This is used to find the quoted update number in marshalled version 1 update descriptors
+var quotedUpdateNumberRegex = regexp.MustCompile(`(?m)^update_number: "(\d+)"$`)
+
+// This function will marshal the given version 1 update descriptor. Fields are written in the order of the struct
+// fields and bug fixes are sorted by the key. The update number will always have enclosing "" to indicate it is a
+// string. So they are removed from the update_number field only. Quotes in other fields are kept.
+func MarshalUpdateDescriptor(updateDescriptor *UpdateDescriptor) ([]byte, error) {
+	data, err := yaml.Marshal(&updateDescriptor)
+	if err != nil {
+		return nil, err
+	}
+	return quotedUpdateNumberRegex.ReplaceAll(data, []byte("update_number: $1")), nil
+}
+
+// This function will marshal the given version 2 update descriptor. Unlike version 1 descriptors, enclosing "" of the
+// update number are kept.
+func MarshalUpdateDescriptorV2(updateDescriptor *UpdateDescriptorV2) ([]byte, error) {
+	return yaml.Marshal(updateDescriptor)
+}
@@ -0,0 +1,179 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestGetDescriptorVersion(t *testing.T) {
+	version, err := GetDescriptorVersion([]byte("update_number: 0001\n"))
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error: %v", err)
+	}
+	if version != constant.DESCRIPTOR_VERSION_1 {
+		t.Errorf("Test failed, expected: %s, actual: %s", constant.DESCRIPTOR_VERSION_1, version)
+	}
+
+	version, err = GetDescriptorVersion([]byte("descriptor_version: 2\nupdate_number: 0001\n"))
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error: %v", err)
+	}
+	if version != constant.DESCRIPTOR_VERSION_2 {
+		t.Errorf("Test failed, expected: %s, actual: %s", constant.DESCRIPTOR_VERSION_2, version)
+	}
+
+	_, err = GetDescriptorVersion([]byte("descriptor_version: 3\n"))
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+}
+
+func TestMigrateUpdateDescriptor(t *testing.T) {
+	updateDescriptor := UpdateDescriptor{
+		Update_number: "0001",
+		Platform_version: "4.4.0",
+		Platform_name: "wilkes",
+		Applies_to: "wso2esb-4.9.0, wso2am-1.10.0",
+		Bug_fixes: map[string]string{
+			"N/A": "N/A",
+		},
+		Description: "sample description",
+	}
+	updateDescriptor.File_changes.Modified_files = []string{"a/b/c.jar"}
+
+	updateDescriptorV2 := MigrateUpdateDescriptor(&updateDescriptor)
+	if updateDescriptorV2.DescriptorVersion != constant.DESCRIPTOR_VERSION_2 {
+		t.Errorf("Test failed, expected: %s, actual: %s", constant.DESCRIPTOR_VERSION_2, updateDescriptorV2.DescriptorVersion)
+	}
+	if len(updateDescriptorV2.AppliesTo) != 2 || updateDescriptorV2.AppliesTo[1] != "wso2am-1.10.0" {
+		t.Errorf("Test failed, unexpected applies_to: %v", updateDescriptorV2.AppliesTo)
+	}
+	if len(updateDescriptorV2.FileChanges.ModifiedFiles) != 1 {
+		t.Errorf("Test failed, unexpected modified_files: %v", updateDescriptorV2.FileChanges.ModifiedFiles)
+	}
+	err := ValidateUpdateDescriptorV2(updateDescriptorV2)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+
+	updateDescriptorV1 := ConvertToUpdateDescriptorV1(updateDescriptorV2)
+	if updateDescriptorV1.Applies_to != updateDescriptor.Applies_to {
+		t.Errorf("Test failed, expected: %s, actual: %s", updateDescriptor.Applies_to, updateDescriptorV1.Applies_to)
+	}
+}
+
+func TestValidateUpdateDescriptorV2(t *testing.T) {
+	updateDescriptor := UpdateDescriptorV2{
+		UpdateNumber: "0001",
+		PlatformVersion: "4.4.0",
+		PlatformName: "wilkes",
+		AppliesTo: []string{"wso2esb-4.9.0"},
+		BugFixes: map[string]string{
+			"N/A": "N/A",
+		},
+		Description: "sample description",
+	}
+	err := ValidateUpdateDescriptorV2(&updateDescriptor)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	updateDescriptor.DescriptorVersion = constant.DESCRIPTOR_VERSION_2
+	err = ValidateUpdateDescriptorV2(&updateDescriptor)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+
+	updateDescriptor.SecuritySeverity = "urgent"
+	err = ValidateUpdateDescriptorV2(&updateDescriptor)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	updateDescriptor.SecuritySeverity = constant.SECURITY_SEVERITY_HIGH
+	updateDescriptor.AppliesTo = []string{}
+	err = ValidateUpdateDescriptorV2(&updateDescriptor)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+}
+
+func TestValidateUpdateDependencies(t *testing.T) {
+	err := ValidateUpdateDependencies("0045", nil, nil)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+
+	err = ValidateUpdateDependencies("0045", []string{"0031"}, []string{"0012"})
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+
+	err = ValidateUpdateDependencies("0045", []string{"31"}, nil)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidateUpdateDependencies("0045", nil, []string{"0045"})
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+}
+
+func TestFindDependencyCycle(t *testing.T) {
+	dependencyGraph := map[string][]string{
+		"0045": {"0031"},
+		"0031": {"0012"},
+		"0012": {},
+	}
+	cycle := FindDependencyCycle("0045", dependencyGraph)
+	if cycle != nil {
+		t.Errorf("Test failed. Unexpected cycle: %v", cycle)
+	}
+
+	dependencyGraph["0012"] = []string{"0045"}
+	cycle = FindDependencyCycle("0045", dependencyGraph)
+	expected := []string{"0045", "0031", "0012", "0045"}
+	if len(cycle) != len(expected) {
+		t.Fatalf("Test failed, expected: %v, actual: %v", expected, cycle)
+	}
+	for i := range expected {
+		if cycle[i] != expected[i] {
+			t.Errorf("Test failed, expected: %v, actual: %v", expected, cycle)
+		}
+	}
+}
+
+func TestValidateProducts(t *testing.T) {
+	productCatalog := map[string]map[string]string{
+		"wso2esb": {"4.9.0": "4.4.0", "4.8.1": "4.2.0"},
+		"wso2am": {"1.10.0": "4.4.0"},
+	}
+	err := ValidateProducts("wso2esb-4.9.0, wso2am-1.10.0", "4.4.0", productCatalog)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+
+	err = ValidateProducts("wso2esb-4.8.1", "4.4.0", productCatalog)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidateProducts("wso2is-5.1.0", "4.4.0", productCatalog)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidateProducts("All the products based on carbon 4.4.1", "4.4.0", productCatalog)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	products := GetProductsOfPlatformVersion("4.4.0", productCatalog)
+	if len(products) != 2 || products[0] != "wso2am-1.10.0" || products[1] != "wso2esb-4.9.0" {
+		t.Errorf("Test failed, unexpected products: %v", products)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// This function will set the file_changes section of the given update-descriptor.yaml content and return the updated
+// content. The content is changed using the YAML node tree. So only the file_changes section is changed and the
+// comments, the order of the keys and the quoting of the other fields are kept as the author wrote them. If the
+// file_changes section is not available, it is added to the end.
+func SetFileChanges(data []byte, fileChanges FileChangesV2) ([]byte, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("Root of the update-descriptor.yaml should be a mapping.")
+	}
+
+	fileChangesNode := &yaml.Node{}
+	err = fileChangesNode.Encode(fileChanges)
+	if err != nil {
+		return nil, err
+	}
+	setMappingValue(document.Content[0], "file_changes", fileChangesNode)
+	return encodeDocument(&document)
+}
+
+// This function will set the update_number field of the given update-descriptor.yaml content and return the updated
+// content. Like SetFileChanges, only the update_number field is changed. The existing quoting of the field is kept so
+// that version 1 descriptors keep unquoted update numbers and version 2 descriptors keep quoted update numbers.
+func SetUpdateNumber(data []byte, updateNumber string) ([]byte, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("Root of the update-descriptor.yaml should be a mapping.")
+	}
+
+	updateNumberNode := &yaml.Node{
+		Kind: yaml.ScalarNode,
+		Value: updateNumber,
+	}
+	mappingNode := document.Content[0]
+	for i := 0; i + 1 < len(mappingNode.Content); i += 2 {
+		if mappingNode.Content[i].Value == "update_number" && mappingNode.Content[i + 1].Kind == yaml.ScalarNode {
+			// An empty tag lets the encoder decide the tag using the value while keeping the style
+			updateNumberNode.Style = mappingNode.Content[i + 1].Style
+		}
+	}
+	setMappingValue(mappingNode, "update_number", updateNumberNode)
+	return encodeDocument(&document)
+}
+
+// This function will set the given fields of the given update-descriptor.yaml content using the values of the same
+// fields in the updated content and return the result. The updated content is the marshalled descriptor struct, so it
+// has the correct quoting of the values. Like SetFileChanges, the comments and the order of the keys in the given
+// content are kept. Values which are not changed are not touched, maps such as bug_fixes are changed key by key and
+// the existing quoting of changed values is kept where possible. Fields which are not in the updated content are
+// removed.
+func SetDescriptorFields(data, updatedData []byte, fields []string) ([]byte, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	updatedDocument := yaml.Node{}
+	err = yaml.Unmarshal(updatedData, &updatedDocument)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range []yaml.Node{document, updatedDocument} {
+		if node.Kind != yaml.DocumentNode || len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+			return nil, errors.New("Root of the update-descriptor.yaml should be a mapping.")
+		}
+	}
+
+	for _, field := range fields {
+		valueNode := getMappingValue(updatedDocument.Content[0], field)
+		if valueNode == nil {
+			removeMappingKey(document.Content[0], field)
+			continue
+		}
+		mergeMappingValue(document.Content[0], field, valueNode)
+	}
+	return encodeDocument(&document)
+}
+
+// This function will set the value of the given key in the given mapping node while keeping as much of the existing
+// value as possible. Equal values are not changed, mappings are merged key by key and the style of changed string
+// scalars is kept.
+func mergeMappingValue(mappingNode *yaml.Node, key string, valueNode *yaml.Node) {
+	oldValueNode := getMappingValue(mappingNode, key)
+	switch {
+	case oldValueNode == nil:
+		setMappingValue(mappingNode, key, valueNode)
+	case oldValueNode.Kind == yaml.MappingNode && valueNode.Kind == yaml.MappingNode:
+		keys := make(map[string]bool)
+		for i := 0; i + 1 < len(valueNode.Content); i += 2 {
+			keys[valueNode.Content[i].Value] = true
+			mergeMappingValue(oldValueNode, valueNode.Content[i].Value, valueNode.Content[i + 1])
+		}
+		for i := len(oldValueNode.Content) - 2; i >= 0; i -= 2 {
+			if !keys[oldValueNode.Content[i].Value] {
+				removeMappingKey(oldValueNode, oldValueNode.Content[i].Value)
+			}
+		}
+	case oldValueNode.Kind == yaml.ScalarNode && valueNode.Kind == yaml.ScalarNode:
+		if oldValueNode.Value == valueNode.Value {
+			return
+		}
+		if oldValueNode.Style != 0 && valueNode.Tag == "!!str" {
+			valueNode.Style = oldValueNode.Style
+		}
+		setMappingValue(mappingNode, key, valueNode)
+	default:
+		if isNodesEqual(oldValueNode, valueNode) {
+			return
+		}
+		setMappingValue(mappingNode, key, valueNode)
+	}
+}
+
+// This function will check whether the given nodes have the same value.
+func isNodesEqual(node, otherNode *yaml.Node) bool {
+	var value, otherValue interface{}
+	if node.Decode(&value) != nil || otherNode.Decode(&otherValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(value, otherValue)
+}
+
+// This function will return the value of the given key in the given mapping node. If the key is not available, nil is
+// returned.
+func getMappingValue(mappingNode *yaml.Node, key string) *yaml.Node {
+	for i := 0; i + 1 < len(mappingNode.Content); i += 2 {
+		if mappingNode.Content[i].Value == key {
+			return mappingNode.Content[i + 1]
+		}
+	}
+	return nil
+}
+
+// This function will remove the given key and its value from the given mapping node.
+func removeMappingKey(mappingNode *yaml.Node, key string) {
+	for i := 0; i + 1 < len(mappingNode.Content); i += 2 {
+		if mappingNode.Content[i].Value == key {
+			mappingNode.Content = append(mappingNode.Content[:i], mappingNode.Content[i + 2:]...)
+			return
+		}
+	}
+}
+
+// This function will encode the given document using 2 spaces for indentation.
+func encodeDocument(document *yaml.Node) ([]byte, error) {
+	buffer := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(document)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error occurred while encoding the update-descriptor.yaml. %v", err))
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// This function will set the value of the given key in the given mapping node. Comments of the existing value are
+// moved to the new value. If the key is not available, the key and the value are added to the end of the mapping.
+func setMappingValue(mappingNode *yaml.Node, key string, valueNode *yaml.Node) {
+	// Content of a mapping node contains keys and values one after the other
+	for i := 0; i + 1 < len(mappingNode.Content); i += 2 {
+		if mappingNode.Content[i].Value != key {
+			continue
+		}
+		oldValueNode := mappingNode.Content[i + 1]
+		valueNode.HeadComment = oldValueNode.HeadComment
+		valueNode.LineComment = oldValueNode.LineComment
+		valueNode.FootComment = oldValueNode.FootComment
+		mappingNode.Content[i + 1] = valueNode
+		return
+	}
+	keyNode := &yaml.Node{
+		Kind: yaml.ScalarNode,
+		Tag: "!!str",
+		Value: key,
+	}
+	mappingNode.Content = append(mappingNode.Content, keyNode, valueNode)
+}
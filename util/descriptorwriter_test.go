@@ -0,0 +1,146 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestSetFileChanges(t *testing.T) {
+	data := []byte(`# Update for the ESB
+update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+description: 'Fixes "quoted": values.'
+bug_fixes:
+  CARBON-1: Fix something # Reported by a customer
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files: []
+`)
+	updatedData, err := SetFileChanges(data, FileChangesV2{
+		AddedFiles: []string{"lib/a.jar"},
+		RemovedFiles: []string{},
+		ModifiedFiles: []string{"lib/b.jar", "lib/c.jar"},
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	expected := `# Update for the ESB
+update_number: 0001
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+description: 'Fixes "quoted": values.'
+bug_fixes:
+  CARBON-1: Fix something # Reported by a customer
+file_changes:
+  added_files:
+    - lib/a.jar
+  removed_files: []
+  modified_files:
+    - lib/b.jar
+    - lib/c.jar
+`
+	if string(updatedData) != expected {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, string(updatedData))
+	}
+}
+
+func TestSetFileChangesWithoutFileChanges(t *testing.T) {
+	data := []byte(`descriptor_version: "2"
+update_number: "0001"
+`)
+	updatedData, err := SetFileChanges(data, FileChangesV2{
+		AddedFiles: []string{},
+		RemovedFiles: []string{},
+		ModifiedFiles: []string{"lib/b.jar"},
+	})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	expected := `descriptor_version: "2"
+update_number: "0001"
+file_changes:
+  added_files: []
+  removed_files: []
+  modified_files:
+    - lib/b.jar
+`
+	if string(updatedData) != expected {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, string(updatedData))
+	}
+	if _, err := SetFileChanges([]byte("- a\n- b\n"), FileChangesV2{}); err == nil {
+		t.Error("Test failed. Expected an error when the root is not a mapping")
+	}
+}
+
+func TestSetUpdateNumber(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected string
+	}{
+		{"# Comment\nupdate_number: ADD_UPDATE_NUMBER_HERE\nplatform_version: 4.4.0\n",
+			"# Comment\nupdate_number: 0008\nplatform_version: 4.4.0\n"},
+		{"descriptor_version: \"2\"\nupdate_number: \"\"\n", "descriptor_version: \"2\"\nupdate_number: \"0008\"\n"},
+		{"platform_version: 4.4.0\n", "platform_version: 4.4.0\nupdate_number: 0008\n"},
+	}
+	for _, testCase := range testCases {
+		updatedData, err := SetUpdateNumber([]byte(testCase.data), "0008")
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if string(updatedData) != testCase.expected {
+			t.Errorf("Test failed, expected: %s, actual: %s", testCase.expected, string(updatedData))
+		}
+	}
+}
+
+func TestSetDescriptorFields(t *testing.T) {
+	data := `# Update of the ESB
+update_number: 0001 # Reserved by the team
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+requires:
+- "0010"
+bug_fixes:
+  # Main fix
+  CARBON-1: Fix something
+  ADD_JIRA_KEY_HERE: ADD_JIRA_SUMMARY_HERE
+description: |
+  Fixes something.
+`
+	updatedData := `update_number: 0002
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  CARBON-1: Fix something
+  CARBON-2: 'Fix: other'
+description: |
+  Fixes something else.
+`
+	expected := `# Update of the ESB
+update_number: 0002 # Reserved by the team
+platform_version: 4.4.0
+platform_name: wilkes
+applies_to: wso2esb-4.9.0
+bug_fixes:
+  # Main fix
+  CARBON-1: Fix something
+  CARBON-2: 'Fix: other'
+description: |
+  Fixes something else.
+`
+	actual, err := SetDescriptorFields([]byte(data), []byte(updatedData), []string{"update_number", "requires",
+		"bug_fixes", "description"})
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if string(actual) != expected {
+		t.Errorf("Test failed, expected: %s, actual: %s", expected, string(actual))
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// struct which is used to store the patterns of a .wumucignore file. Patterns use the gitignore syntax.
+type IgnorePatterns struct {
+	patterns []ignorePattern
+}
+
+// struct which is used to store a single pattern of a .wumucignore file
+type ignorePattern struct {
+	regex     *regexp.Regexp
+	// Whether the pattern starts with ! and re-includes the matching paths
+	isNegated bool
+	// Whether the pattern ends with / and matches directories only
+	isDirOnly bool
+}
+
+// This function will read the patterns in the given .wumucignore file. The default patterns are added before the
+// patterns in the file so that they can be re-included using ! patterns. If the file does not exist, only the default
+// patterns are returned.
+func LoadIgnorePatterns(ignoreFilePath string) (*IgnorePatterns, error) {
+	ignorePatterns := ParseIgnorePatterns([]byte(strings.Join(IgnorePatterns_Default, "\n")))
+	if len(ignoreFilePath) == 0 {
+		return ignorePatterns, nil
+	}
+	data, err := ioutil.ReadFile(ignoreFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ignorePatterns, nil
+		}
+		return nil, err
+	}
+	ignorePatterns.patterns = append(ignorePatterns.patterns, ParseIgnorePatterns(data).patterns...)
+	return ignorePatterns, nil
+}
+
+// This function will parse the given patterns. Blank lines and lines starting with # are skipped.
+func ParseIgnorePatterns(data []byte) *IgnorePatterns {
+	ignorePatterns := &IgnorePatterns{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pattern := ignorePattern{}
+		if strings.HasPrefix(line, "!") {
+			pattern.isNegated = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			pattern.isDirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if len(line) == 0 {
+			continue
+		}
+		// Patterns without a / in the middle match the name at any level. Others are relative to the root.
+		prefix := "^(.*/)?"
+		if strings.Contains(line, "/") {
+			prefix = "^"
+			line = strings.TrimPrefix(line, "/")
+		}
+		regex, err := regexp.Compile(prefix + convertIgnorePattern(line) + "$")
+		if err != nil {
+			logger.Debug(fmt.Sprintf("Skipping invalid ignore pattern '%s'. %v", line, err))
+			continue
+		}
+		pattern.regex = regex
+		ignorePatterns.patterns = append(ignorePatterns.patterns, pattern)
+	}
+	return ignorePatterns
+}
+
+// This function will return the given path or the top most directory of it which is ignored. An empty string is
+// returned if the path is not ignored. Paths should be relative to the root and use / as the separator. Like git, a
+// path cannot be re-included if one of its parent directories is ignored.
+func (ignorePatterns *IgnorePatterns) GetIgnoredPath(relativePath string, isDir bool) string {
+	if ignorePatterns == nil {
+		return ""
+	}
+	relativePath = strings.Trim(relativePath, "/")
+	parts := strings.Split(relativePath, "/")
+	for i := range parts {
+		currentPath := strings.Join(parts[:i + 1], "/")
+		if ignorePatterns.isMatched(currentPath, isDir || i < len(parts) - 1) {
+			return currentPath
+		}
+	}
+	return ""
+}
+
+// This function will check whether the given path is ignored.
+func (ignorePatterns *IgnorePatterns) IsIgnored(relativePath string, isDir bool) bool {
+	return len(ignorePatterns.GetIgnoredPath(relativePath, isDir)) > 0
+}
+
+// This function will check whether the last pattern which matches the given path ignores it.
+func (ignorePatterns *IgnorePatterns) isMatched(relativePath string, isDir bool) bool {
+	isIgnored := false
+	for _, pattern := range ignorePatterns.patterns {
+		if pattern.isDirOnly && !isDir {
+			continue
+		}
+		if pattern.regex.MatchString(relativePath) {
+			isIgnored = !pattern.isNegated
+		}
+	}
+	return isIgnored
+}
+
+// This function will convert the given gitignore pattern to a regular expression. * and ? do not match /, and ** matches
+// any number of directories.
+func convertIgnorePattern(pattern string) string {
+	regex := ""
+	for i := 0; i < len(pattern); i++ {
+		switch character := pattern[i]; character {
+		case '*':
+			if strings.HasPrefix(pattern[i:], "**/") {
+				regex += "(.*/)?"
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "**") {
+				regex += ".*"
+				i++
+			} else {
+				regex += "[^/]*"
+			}
+		case '?':
+			regex += "[^/]"
+		case '[':
+			end := strings.Index(pattern[i:], "]")
+			if end < 0 {
+				regex += regexp.QuoteMeta(string(character))
+				continue
+			}
+			class := pattern[i + 1:i + end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			regex += "[" + class + "]"
+			i += end
+		case '\\':
+			if i + 1 < len(pattern) {
+				i++
+				regex += regexp.QuoteMeta(string(pattern[i]))
+			}
+		default:
+			regex += regexp.QuoteMeta(string(character))
+		}
+	}
+	return regex
+}
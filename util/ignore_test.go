@@ -0,0 +1,45 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestGetIgnoredPath(t *testing.T) {
+	ignorePatterns := ParseIgnorePatterns([]byte(`# Build outputs
+target/
+*.log
+!important.log
+/build.sh
+docs/**/*.md
+\#notes
+`))
+	ignorePatterns.patterns = append(ParseIgnorePatterns([]byte(".git/\n*.swp")).patterns, ignorePatterns.patterns...)
+
+	testCases := []struct {
+		relativePath string
+		isDir        bool
+		expected     string
+	}{
+		{"a.jar", false, ""},
+		{"target", true, "target"},
+		{"target", false, ""},
+		{"module/target/classes/a.class", false, "module/target"},
+		{"server.log", false, "server.log"},
+		{"lib/important.log", false, ""},
+		{"build.sh", false, "build.sh"},
+		{"scripts/build.sh", false, ""},
+		{"docs/README.md", false, "docs/README.md"},
+		{"docs/a/b/c.md", false, "docs/a/b/c.md"},
+		{"#notes", false, "#notes"},
+		{".git/objects/ab", false, ".git"},
+		{"store/.list.jag.swp", false, "store/.list.jag.swp"},
+	}
+	for _, testCase := range testCases {
+		actual := ignorePatterns.GetIgnoredPath(testCase.relativePath, testCase.isDir)
+		if actual != testCase.expected {
+			t.Errorf("Test failed for %s, expected: %s, actual: %s", testCase.relativePath, testCase.expected, actual)
+		}
+	}
+}
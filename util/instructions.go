@@ -0,0 +1,92 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// struct which is used to read the structured instructions.yaml file
+type Instructions struct {
+	// Configuration files which should be changed after applying the update
+	ConfigChanges   []ConfigChange   `yaml:"config_changes,omitempty"`
+	// Database scripts which should be executed after applying the update
+	DatabaseScripts []DatabaseScript `yaml:"database_scripts,omitempty"`
+	// Whether the server should be restarted after applying the update
+	RestartRequired bool             `yaml:"restart_required"`
+	// Notes for clustered deployments
+	ClusterNotes    string           `yaml:"cluster_notes,omitempty"`
+}
+
+// struct which is used to store a change of a configuration file. File is relative to carbon.home.
+type ConfigChange struct {
+	File        string `yaml:"file"`
+	Description string `yaml:"description"`
+}
+
+// struct which is used to store a database script. File is relative to carbon.home.
+type DatabaseScript struct {
+	File        string `yaml:"file"`
+	Database    string `yaml:"database,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// This function will unmarshal and validate the given instructions.yaml content. Unknown fields are reported as errors
+// so that typos in the field names are not ignored.
+func UnmarshalInstructions(data []byte) (*Instructions, error) {
+	instructions := Instructions{}
+	err := yaml.UnmarshalStrict(data, &instructions)
+	if err != nil {
+		return nil, err
+	}
+	err = ValidateInstructions(&instructions)
+	if err != nil {
+		return nil, err
+	}
+	return &instructions, nil
+}
+
+// This function will validate the given instructions. Files should be relative paths inside carbon.home.
+func ValidateInstructions(instructions *Instructions) error {
+	for i, configChange := range instructions.ConfigChanges {
+		if err := validateInstructionsPath("config_changes", i, configChange.File); err != nil {
+			return err
+		}
+		if len(strings.TrimSpace(configChange.Description)) == 0 {
+			return errors.New(fmt.Sprintf("'description' of entry %d in 'config_changes' is empty.", i + 1))
+		}
+	}
+	for i, databaseScript := range instructions.DatabaseScripts {
+		if err := validateInstructionsPath("database_scripts", i, databaseScript.File); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// This function will return the configuration files referenced in the given instructions.
+func (instructions *Instructions) GetConfigFiles() []string {
+	configFiles := make([]string, 0)
+	for _, configChange := range instructions.ConfigChanges {
+		configFiles = append(configFiles, path.Clean(configChange.File))
+	}
+	return configFiles
+}
+
+// This function will check whether the file of the given entry is a relative path inside carbon.home.
+func validateInstructionsPath(fieldName string, index int, file string) error {
+	if len(strings.TrimSpace(file)) == 0 {
+		return errors.New(fmt.Sprintf("'file' of entry %d in '%s' is empty.", index + 1, fieldName))
+	}
+	cleanPath := path.Clean(file)
+	if path.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+		return errors.New(fmt.Sprintf("'file' of entry %d in '%s' should be relative to carbon.home: '%s'.",
+			index + 1, fieldName, file))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestUnmarshalInstructions(t *testing.T) {
+	instructions, err := UnmarshalInstructions([]byte(`config_changes:
+- file: repository/conf/./carbon.xml
+  description: Set the HostName.
+database_scripts:
+- file: dbscripts/mysql.sql
+  database: mysql
+restart_required: true
+cluster_notes: Apply to all nodes.
+`))
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	configFiles := instructions.GetConfigFiles()
+	if len(configFiles) != 1 || configFiles[0] != "repository/conf/carbon.xml" {
+		t.Errorf("Test failed, expected: %s, actual: %v", "repository/conf/carbon.xml", configFiles)
+	}
+	if !instructions.RestartRequired || instructions.DatabaseScripts[0].Database != "mysql" {
+		t.Errorf("Test failed. Unexpected instructions: %v", instructions)
+	}
+
+	for _, data := range []string{
+		"config_change:\n- file: repository/conf/carbon.xml\n",
+		"config_changes:\n- file: repository/conf/carbon.xml\n",
+		"config_changes:\n- file: /repository/conf/carbon.xml\n  description: Set the HostName.\n",
+		"database_scripts:\n- file: ../mysql.sql\n",
+	} {
+		if _, err := UnmarshalInstructions([]byte(data)); err == nil {
+			t.Errorf("Test failed. Expected an error for: %s", data)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/wum-uc/constant"
+	"gopkg.in/yaml.v2"
+)
+
+// struct which is used to store the record of applied updates inside a distribution
+type Ledger struct {
+	AppliedUpdates []AppliedUpdate `yaml:"applied_updates"`
+}
+
+// struct which is used to store the details of a single applied update
+type AppliedUpdate struct {
+	UpdateName      string        `yaml:"update_name"`
+	UpdateNumber    string        `yaml:"update_number"`
+	PlatformVersion string        `yaml:"platform_version"`
+	AppliedTime     string        `yaml:"applied_time"`
+	ToolVersion     string        `yaml:"tool_version"`
+	Files           []AppliedFile `yaml:"files"`
+}
+
+// struct which is used to store the md5 hashes of a file before and after applying an update. Empty hash means the file
+// did not exist.
+type AppliedFile struct {
+	Path      string `yaml:"path"`
+	Md5Before string `yaml:"md5_before,omitempty"`
+	Md5After  string `yaml:"md5_after,omitempty"`
+}
+
+// struct which is used to store the details of a file which does not match the ledger
+type DriftedFile struct {
+	Path         string
+	UpdateName   string
+	ExpectedMd5  string
+	ActualMd5    string
+}
+
+// This function will return the location of the ledger file in the given CARBON_HOME.
+func GetLedgerPath(carbonHome string) string {
+	return filepath.Join(carbonHome, constant.LEDGER_DIRECTORY, constant.LEDGER_FILE)
+}
+
+// This function will read the ledger in the given CARBON_HOME. If the ledger does not exist, an empty ledger is
+// returned.
+func LoadLedger(carbonHome string) (*Ledger, error) {
+	ledger := Ledger{}
+	ledgerPath := GetLedgerPath(carbonHome)
+	logger.Debug(fmt.Sprintf("ledgerPath: %s", ledgerPath))
+	exists, err := IsFileExists(ledgerPath)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		logger.Debug("Ledger not found.")
+		return &ledger, nil
+	}
+	data, err := ioutil.ReadFile(ledgerPath)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(data, &ledger)
+	if err != nil {
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+// This function will save the given ledger in the given CARBON_HOME. The ledger is written to a temporary file first
+// and then renamed so that the ledger will not be corrupted if an error occurs while writing.
+func SaveLedger(carbonHome string, ledger *Ledger) error {
+	ledgerPath := GetLedgerPath(carbonHome)
+	err := CreateDirectory(filepath.Dir(ledgerPath))
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(ledger)
+	if err != nil {
+		return err
+	}
+	tempLedgerPath := ledgerPath + ".tmp"
+	err = ioutil.WriteFile(tempLedgerPath, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tempLedgerPath, ledgerPath)
+}
+
+// This function will check whether the update with the given name is already in the ledger.
+func IsUpdateApplied(ledger *Ledger, updateName string) bool {
+	for _, appliedUpdate := range ledger.AppliedUpdates {
+		if appliedUpdate.UpdateName == updateName {
+			return true
+		}
+	}
+	return false
+}
+
+// This function will check whether all updates in the requires field of the given update descriptor are in the
+// ledger. Only the applied updates with the same platform version are considered.
+func CheckPrerequisites(ledger *Ledger, updateDescriptor *UpdateDescriptor) error {
+	appliedUpdateNumbers := make(map[string]bool)
+	for _, appliedUpdate := range ledger.AppliedUpdates {
+		if appliedUpdate.PlatformVersion == updateDescriptor.Platform_version {
+			appliedUpdateNumbers[appliedUpdate.UpdateNumber] = true
+		}
+	}
+	missingUpdates := make([]string, 0)
+	for _, requiredUpdate := range updateDescriptor.Requires {
+		if !appliedUpdateNumbers[requiredUpdate] {
+			missingUpdates = append(missingUpdates, requiredUpdate)
+		}
+	}
+	if len(missingUpdates) > 0 {
+		return errors.New(fmt.Sprintf("Required update(s) not applied: %s.", strings.Join(missingUpdates, ", ")))
+	}
+	return nil
+}
+
+// This function will compare the files in the given CARBON_HOME with the ledger and return all files which do not
+// match the state recorded by the last update which changed them.
+func FindDrift(carbonHome string, ledger *Ledger) ([]DriftedFile, error) {
+	// Find the expected state of each file. Later updates override the earlier updates.
+	expectedFiles := make(map[string]DriftedFile)
+	for _, appliedUpdate := range ledger.AppliedUpdates {
+		for _, appliedFile := range appliedUpdate.Files {
+			expectedFiles[appliedFile.Path] = DriftedFile{
+				Path: appliedFile.Path,
+				UpdateName: appliedUpdate.UpdateName,
+				ExpectedMd5: appliedFile.Md5After,
+			}
+		}
+	}
+	paths := make([]string, 0)
+	for filePath := range expectedFiles {
+		paths = append(paths, filePath)
+	}
+	sort.Strings(paths)
+
+	driftedFiles := make([]DriftedFile, 0)
+	for _, filePath := range paths {
+		expectedFile := expectedFiles[filePath]
+		actualMd5, err := GetMD5IfExists(filepath.Join(carbonHome, filepath.FromSlash(filePath)))
+		if err != nil {
+			return nil, err
+		}
+		if actualMd5 != expectedFile.ExpectedMd5 {
+			expectedFile.ActualMd5 = actualMd5
+			driftedFiles = append(driftedFiles, expectedFile)
+		}
+	}
+	return driftedFiles, nil
+}
+
+// This function will return the location of the given relative path inside the given directory. Paths in update zips
+// and in update descriptors are not trusted, so an error is returned if the path is absolute or if the location is
+// outside the directory. Ex: ../../etc/passwd
+func GetPathInDirectory(directory, relativePath string) (string, error) {
+	if filepath.IsAbs(filepath.FromSlash(relativePath)) || strings.HasPrefix(filepath.ToSlash(relativePath), "/") {
+		return "", errors.New(fmt.Sprintf("'%s' is an absolute path.", relativePath))
+	}
+	directory = filepath.Clean(directory)
+	location := filepath.Clean(filepath.Join(directory, filepath.FromSlash(relativePath)))
+	pathInDirectory, err := filepath.Rel(directory, location)
+	if err != nil {
+		return "", err
+	}
+	if pathInDirectory == "." || pathInDirectory == ".." || strings.HasPrefix(pathInDirectory, ".." + string(filepath.Separator)) {
+		return "", errors.New(fmt.Sprintf("'%s' is not inside '%s'.", relativePath, directory))
+	}
+	return location, nil
+}
+
+// This will return the md5 hash of the file in the given filepath. If the file does not exist, an empty string is
+// returned.
+func GetMD5IfExists(filepath string) (string, error) {
+	exists, err := IsFileExists(filepath)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil
+	}
+	return GetMD5(filepath)
+}
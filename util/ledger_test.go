@@ -0,0 +1,115 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckPrerequisites(t *testing.T) {
+	ledger := Ledger{
+		AppliedUpdates: []AppliedUpdate{
+			{UpdateName: "WSO2-CARBON-UPDATE-4.4.0-0031", UpdateNumber: "0031", PlatformVersion: "4.4.0"},
+			{UpdateName: "WSO2-CARBON-UPDATE-4.2.0-0012", UpdateNumber: "0012", PlatformVersion: "4.2.0"},
+		},
+	}
+	updateDescriptor := UpdateDescriptor{
+		Update_number: "0045",
+		Platform_version: "4.4.0",
+		Requires: []string{"0031"},
+	}
+	err := CheckPrerequisites(&ledger, &updateDescriptor)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+
+	updateDescriptor.Requires = []string{"0031", "0012"}
+	err = CheckPrerequisites(&ledger, &updateDescriptor)
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	if !IsUpdateApplied(&ledger, "WSO2-CARBON-UPDATE-4.4.0-0031") {
+		t.Error("Test failed. Update expected to be applied")
+	}
+	if IsUpdateApplied(&ledger, "WSO2-CARBON-UPDATE-4.4.0-0045") {
+		t.Error("Test failed. Update not expected to be applied")
+	}
+}
+
+func TestFindDrift(t *testing.T) {
+	carbonHome, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(carbonHome)
+
+	filePath := filepath.Join(carbonHome, "a.jar")
+	err = ioutil.WriteFile(filePath, []byte("content"), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	md5Hash, err := GetMD5(filePath)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+
+	ledger := Ledger{
+		AppliedUpdates: []AppliedUpdate{
+			{UpdateName: "WSO2-CARBON-UPDATE-4.4.0-0001", Files: []AppliedFile{
+				{Path: "a.jar", Md5After: "old"},
+				{Path: "b.jar", Md5Before: "removed"},
+			}},
+			{UpdateName: "WSO2-CARBON-UPDATE-4.4.0-0002", Files: []AppliedFile{
+				{Path: "a.jar", Md5Before: "old", Md5After: md5Hash},
+			}},
+		},
+	}
+	driftedFiles, err := FindDrift(carbonHome, &ledger)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+	if len(driftedFiles) != 0 {
+		t.Errorf("Test failed. Unexpected drift: %v", driftedFiles)
+	}
+
+	err = ioutil.WriteFile(filePath, []byte("changed"), 0600)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	driftedFiles, err = FindDrift(carbonHome, &ledger)
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+	if len(driftedFiles) != 1 || driftedFiles[0].UpdateName != "WSO2-CARBON-UPDATE-4.4.0-0002" {
+		t.Errorf("Test failed. Unexpected drift: %v", driftedFiles)
+	}
+}
+
+func TestGetPathInDirectory(t *testing.T) {
+	directory := filepath.Join("tmp", "wso2esb-4.9.0")
+	testCases := []struct {
+		relativePath string
+		expected     string
+	}{
+		{"repository/components/plugins/a.jar", filepath.Join(directory, "repository", "components", "plugins", "a.jar")},
+		{"bin/../lib/a.jar", filepath.Join(directory, "lib", "a.jar")},
+		{"../../etc/passwd", ""},
+		{"bin/../../wso2esb-4.9.0-old/a.jar", ""},
+		{"/etc/passwd", ""},
+		{"..", ""},
+		{".", ""},
+	}
+	for _, testCase := range testCases {
+		actual, err := GetPathInDirectory(directory, testCase.relativePath)
+		if len(testCase.expected) == 0 && err == nil {
+			t.Errorf("Test failed, expected an error for '%s', actual: %s", testCase.relativePath, actual)
+		}
+		if len(testCase.expected) > 0 && actual != testCase.expected {
+			t.Errorf("Test failed, expected: %s, actual: %s (%v)", testCase.expected, actual, err)
+		}
+	}
+}
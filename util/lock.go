@@ -0,0 +1,112 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Interval between two attempts to create a lock file
+const lockRetryInterval = 100 * time.Millisecond
+
+// This function will create the given lock file. The lock file is created only if it does not exist, so only one
+// process can hold the lock at a time even if the file is in a shared directory. If the lock file already exists, this
+// function will wait until it is removed or the timeout expires. Lock files older than staleAfter are considered as
+// left by crashed runs and are taken over. A unique token is written to the lock file and the returned function
+// removes the lock file only if it still contains the token, so a lock which is taken over by another process is not
+// removed.
+func AcquireLock(lockFilePath string, timeout, staleAfter time.Duration) (func(), error) {
+	id, token, err := getLockToken()
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		file, err := os.OpenFile(lockFilePath, os.O_WRONLY | os.O_CREATE | os.O_EXCL, 0600)
+		if err == nil {
+			_, err = file.Write(token)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(lockFilePath)
+				return nil, err
+			}
+			logger.Debug(fmt.Sprintf("Lock acquired: %s", lockFilePath))
+			return func() {
+				releaseLock(lockFilePath, id, token)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		fileInfo, err := os.Stat(lockFilePath)
+		if err == nil && time.Since(fileInfo.ModTime()) > staleAfter {
+			logger.Debug(fmt.Sprintf("Taking over stale lock: %s", lockFilePath))
+			removeStaleLock(lockFilePath, id, staleAfter)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New(fmt.Sprintf("Timed out while waiting for the lock '%s'. If no other process is "+
+				"using it, remove the file and try again.", lockFilePath))
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// This function will return a random id and a token which are unique to this lock attempt. The host name and the
+// process id are added to the token so that the owner of a lock file can be found.
+func getLockToken() (string, []byte, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(randomBytes)
+	hostname, _ := os.Hostname()
+	return id, []byte(fmt.Sprintf("%s:%d:%s\n", hostname, os.Getpid(), id)), nil
+}
+
+// This function will move the given lock file to a unique location so that only one process can take it away. Renaming
+// is atomic, so if two processes find the same stale lock, only one of them can move it. The moved file is checked
+// again because another process may have replaced the stale lock with a fresh one after it was found. A fresh lock is
+// moved back unless another lock file was created in the meantime.
+func removeStaleLock(lockFilePath, id string, staleAfter time.Duration) {
+	movedPath := lockFilePath + "." + id
+	if err := os.Rename(lockFilePath, movedPath); err != nil {
+		logger.Debug(fmt.Sprintf("Stale lock already taken by another process: %v", err))
+		return
+	}
+	fileInfo, err := os.Stat(movedPath)
+	if err == nil && time.Since(fileInfo.ModTime()) <= staleAfter {
+		logger.Debug(fmt.Sprintf("Lock is not stale anymore. Restoring: %s", lockFilePath))
+		// Link fails if the lock file exists, so a lock created in the meantime is not replaced
+		os.Link(movedPath, lockFilePath)
+	}
+	os.Remove(movedPath)
+}
+
+// This function will remove the given lock file if it contains the given token. The lock file is moved to a unique
+// location first so that a lock which is created by another process after the token is checked is not removed.
+func releaseLock(lockFilePath, id string, token []byte) {
+	movedPath := lockFilePath + "." + id
+	if err := os.Rename(lockFilePath, movedPath); err != nil {
+		logger.Debug(fmt.Sprintf("Lock not found while releasing: %v", err))
+		return
+	}
+	data, err := ioutil.ReadFile(movedPath)
+	if err != nil || !bytes.Equal(data, token) {
+		logger.Debug(fmt.Sprintf("Lock is owned by another process. Restoring: %s", lockFilePath))
+		os.Link(movedPath, lockFilePath)
+	} else {
+		logger.Debug(fmt.Sprintf("Lock released: %s", lockFilePath))
+	}
+	os.Remove(movedPath)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireLock(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+	lockFilePath := filepath.Join(root, "update-registry.json.lock")
+
+	release, err := AcquireLock(lockFilePath, time.Second, time.Hour)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if _, err := AcquireLock(lockFilePath, 200 * time.Millisecond, time.Hour); err == nil {
+		t.Error("Test failed. Expected a timeout while the lock is held")
+	}
+	release()
+	if _, err := os.Stat(lockFilePath); !os.IsNotExist(err) {
+		t.Error("Test failed. Lock file should be removed")
+	}
+}
+
+func TestAcquireStaleLock(t *testing.T) {
+	root, err := ioutil.TempDir("", "wum-uc-test")
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	defer os.RemoveAll(root)
+	lockFilePath := filepath.Join(root, "update-registry.json.lock")
+
+	// The first owner crashes and its lock becomes stale
+	releaseFirst, err := AcquireLock(lockFilePath, time.Second, time.Hour)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	staleTime := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(lockFilePath, staleTime, staleTime)
+
+	releaseSecond, err := AcquireLock(lockFilePath, time.Second, time.Hour)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	secondToken, _ := ioutil.ReadFile(lockFilePath)
+
+	// Releasing the taken over lock should not remove the lock of the new owner
+	releaseFirst()
+	token, err := ioutil.ReadFile(lockFilePath)
+	if err != nil || string(token) != string(secondToken) {
+		t.Errorf("Test failed, expected: %s, actual: %s (%v)", string(secondToken), string(token), err)
+	}
+	releaseSecond()
+	if _, err := os.Stat(lockFilePath); !os.IsNotExist(err) {
+		t.Error("Test failed. Lock file should be removed")
+	}
+	files, _ := ioutil.ReadDir(root)
+	if len(files) != 0 {
+		t.Errorf("Test failed. Unexpected files left: %d", len(files))
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wso2/wum-uc/constant"
+	"gopkg.in/yaml.v3"
+)
+
+// struct which is used to store the location of a placeholder in the update-descriptor.yaml
+type Placeholder struct {
+	// Path of the field which contains the placeholder. Ex: bug_fixes, applies_to[1]
+	Field       string
+	// Placeholder value. Ex: ADD_JIRA_KEY_HERE
+	Placeholder string
+	// Line number of the placeholder. Line numbers start from 1.
+	Line        int
+}
+
+// This function will return the placeholders which are added to the update-descriptor.yaml by the init command.
+func GetPlaceholders() []string {
+	return []string{constant.UPDATE_NO_DEFAULT, constant.PLATFORM_NAME_DEFAULT, constant.PLATFORM_VERSION_DEFAULT,
+		constant.APPLIES_TO_DEFAULT, strings.TrimSpace(constant.DESCRIPTION_DEFAULT), constant.JIRA_KEY_DEFAULT,
+		constant.JIRA_SUMMARY_DEFAULT}
+}
+
+// This function will parse the given update-descriptor.yaml content and return all placeholders in it with the field
+// and the line number they were found at. Placeholders are returned in the order they appear in the file.
+func FindPlaceholders(data []byte) ([]Placeholder, error) {
+	document := yaml.Node{}
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	placeholders := make([]Placeholder, 0)
+	findPlaceholdersInNode(&document, "", &placeholders)
+	return placeholders, nil
+}
+
+// This function will recursively find the placeholders in the given node. Both keys and values of mappings are checked
+// because the JIRA key placeholder is a key in the bug_fixes field.
+func findPlaceholdersInNode(node *yaml.Node, field string, placeholders *[]Placeholder) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			findPlaceholdersInNode(child, field, placeholders)
+		}
+	case yaml.MappingNode:
+		// Content of a mapping node contains keys and values one after the other
+		for i := 0; i + 1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			valueNode := node.Content[i + 1]
+			childField := keyNode.Value
+			if len(field) > 0 {
+				childField = field + "." + keyNode.Value
+			}
+			// Keys which are placeholders are reported using the parent field. Ex: bug_fixes
+			reportField := field
+			if len(reportField) == 0 {
+				reportField = keyNode.Value
+			}
+			findPlaceholdersInScalar(keyNode, reportField, placeholders)
+			findPlaceholdersInNode(valueNode, childField, placeholders)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			findPlaceholdersInNode(child, field + "[" + strconv.Itoa(i) + "]", placeholders)
+		}
+	case yaml.ScalarNode:
+		findPlaceholdersInScalar(node, field, placeholders)
+	}
+}
+
+// This function will add all placeholders in the value of the given scalar node. In block scalars, the line number is
+// calculated using the line of the placeholder within the value.
+func findPlaceholdersInScalar(node *yaml.Node, field string, placeholders *[]Placeholder) {
+	for _, placeholder := range GetPlaceholders() {
+		index := strings.Index(node.Value, placeholder)
+		if index < 0 {
+			continue
+		}
+		line := node.Line
+		if node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle {
+			// Value of a block scalar starts in the line after the indicator
+			line += 1 + strings.Count(node.Value[:index], "\n")
+		}
+		*placeholders = append(*placeholders, Placeholder{
+			Field: field,
+			Placeholder: placeholder,
+			Line: line,
+		})
+	}
+}
+
+// This function will return a message which contains the given placeholders and their locations.
+func GetPlaceholdersMessage(placeholders []Placeholder) string {
+	lines := make([]string, 0)
+	for _, placeholder := range placeholders {
+		lines = append(lines, fmt.Sprintf("Line %d: '%s' in '%s'", placeholder.Line, placeholder.Placeholder,
+			placeholder.Field))
+	}
+	return strings.Join(lines, "\n")
+}
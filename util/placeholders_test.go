@@ -0,0 +1,53 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestFindPlaceholders(t *testing.T) {
+	data := []byte(`update_number: 0001
+platform_version: 4.4.0
+platform_name: ADD_PLATFORM_NAME_HERE
+applies_to:
+- wso2esb-4.9.0
+- ADD_APPLIES_TO_HERE
+bug_fixes:
+  ADD_JIRA_KEY_HERE: ADD_JIRA_SUMMARY_HERE
+description: |
+  This update fixes an issue.
+  ADD_DESCRIPTION_HERE
+`)
+	placeholders, err := FindPlaceholders(data)
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	expected := []Placeholder{
+		{"platform_name", constant.PLATFORM_NAME_DEFAULT, 3},
+		{"applies_to[1]", constant.APPLIES_TO_DEFAULT, 6},
+		{"bug_fixes", constant.JIRA_KEY_DEFAULT, 8},
+		{"bug_fixes.ADD_JIRA_KEY_HERE", constant.JIRA_SUMMARY_DEFAULT, 8},
+		{"description", "ADD_DESCRIPTION_HERE", 11},
+	}
+	if len(placeholders) != len(expected) {
+		t.Fatalf("Test failed, expected: %v, actual: %v", expected, placeholders)
+	}
+	for i, placeholder := range placeholders {
+		if placeholder != expected[i] {
+			t.Errorf("Test failed, expected: %v, actual: %v", expected[i], placeholder)
+		}
+	}
+}
+
+func TestFindPlaceholdersWithoutPlaceholders(t *testing.T) {
+	placeholders, err := FindPlaceholders([]byte("update_number: 0001\nplatform_version: 4.4.0\n"))
+	if err != nil {
+		t.Fatalf("Test failed. Unexpected error %v", err)
+	}
+	if len(placeholders) != 0 {
+		t.Errorf("Test failed. Unexpected placeholders: %v", placeholders)
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/wso2/wum-uc/constant"
+)
+
+// This function will generate a new key pair of the given type and return the PEM encoded private key and public key.
+func GenerateKeys(keyType string) ([]byte, []byte, error) {
+	var privateKey crypto.Signer
+	switch keyType {
+	case constant.KEY_TYPE_ED25519:
+		_, ed25519PrivateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		privateKey = ed25519PrivateKey
+	case constant.KEY_TYPE_RSA:
+		rsaPrivateKey, err := rsa.GenerateKey(rand.Reader, constant.RSA_KEY_SIZE)
+		if err != nil {
+			return nil, nil, err
+		}
+		privateKey = rsaPrivateKey
+	default:
+		return nil, nil, errors.New(fmt.Sprintf("Key type '%s' is not supported. Supported types are %s and %s.",
+			keyType, constant.KEY_TYPE_ED25519, constant.KEY_TYPE_RSA))
+	}
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(privateKey.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes})
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+	return privateKeyPEM, publicKeyPEM, nil
+}
+
+// This function will return the location of the detached signature of the given file.
+func GetSignaturePath(filePath string) string {
+	return filePath + constant.SIGNATURE_EXTENSION
+}
+
+// This function will sign the file in the given location using the private key in the given PEM file and save the
+// base64 encoded detached signature next to the file. Location of the signature is returned.
+func SignFile(filePath, privateKeyPath string) (string, error) {
+	privateKey, err := readPrivateKey(privateKeyPath)
+	if err != nil {
+		return "", err
+	}
+	digest, err := getSHA256(filePath)
+	if err != nil {
+		return "", err
+	}
+	var signature []byte
+	switch key := privateKey.(type) {
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, digest)
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest)
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.New(fmt.Sprintf("Key type of '%s' is not supported.", privateKeyPath))
+	}
+	signaturePath := GetSignaturePath(filePath)
+	err = ioutil.WriteFile(signaturePath, []byte(base64.StdEncoding.EncodeToString(signature) + "\n"), 0644)
+	if err != nil {
+		return "", err
+	}
+	return signaturePath, nil
+}
+
+// This function will verify the detached signature of the file in the given location using the public key in the
+// given PEM file. An error is returned if the signature is missing or invalid.
+func VerifyFileSignature(filePath, publicKeyPath string) error {
+	publicKey, err := readPublicKey(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	signaturePath := GetSignaturePath(filePath)
+	exists, err := IsFileExists(signaturePath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New(fmt.Sprintf("Signature not found at '%s'.", signaturePath))
+	}
+	data, err := ioutil.ReadFile(signaturePath)
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return errors.New(fmt.Sprintf("Signature at '%s' is not valid. %s", signaturePath, err.Error()))
+	}
+	digest, err := getSHA256(filePath)
+	if err != nil {
+		return err
+	}
+	switch key := publicKey.(type) {
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, digest, signature) {
+			return errors.New(fmt.Sprintf("Signature of '%s' is not valid.", filePath))
+		}
+	case *rsa.PublicKey:
+		err = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature)
+		if err != nil {
+			return errors.New(fmt.Sprintf("Signature of '%s' is not valid.", filePath))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Key type of '%s' is not supported.", publicKeyPath))
+	}
+	return nil
+}
+
+// This function will read the private key in the given PEM file. PKCS#8 and PKCS#1 keys are supported.
+func readPrivateKey(privateKeyPath string) (interface{}, error) {
+	block, err := readPEMBlock(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "RSA PRIVATE KEY" {
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
+
+// This function will read the public key in the given PEM file. PKIX and PKCS#1 keys are supported.
+func readPublicKey(publicKeyPath string) (interface{}, error) {
+	block, err := readPEMBlock(publicKeyPath)
+	if err != nil {
+		return nil, err
+	}
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// This function will read the first PEM block in the given file.
+func readPEMBlock(keyPath string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New(fmt.Sprintf("'%s' is not a PEM file.", keyPath))
+	}
+	return block, nil
+}
+
+// This function will return the SHA-256 hash of the file in the given location.
+func getSHA256(filePath string) ([]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
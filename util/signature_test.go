@@ -0,0 +1,69 @@
+// Copyright (c) 2016, WSO2 Inc. (http://www.wso2.org) All Rights Reserved.
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wso2/wum-uc/constant"
+)
+
+func TestSignFile(t *testing.T) {
+	for _, keyType := range []string{constant.KEY_TYPE_ED25519, constant.KEY_TYPE_RSA} {
+		root, err := ioutil.TempDir("", "wum-uc-test")
+		if err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		defer os.RemoveAll(root)
+
+		privateKey, publicKey, err := GenerateKeys(keyType)
+		if err != nil {
+			t.Fatalf("Test failed for %s. Unexpected error %v", keyType, err)
+		}
+		privateKeyPath := filepath.Join(root, "key.pem")
+		publicKeyPath := filepath.Join(root, "key.pub.pem")
+		updatePath := filepath.Join(root, "WSO2-CARBON-UPDATE-4.4.0-0001.zip")
+		if err := ioutil.WriteFile(privateKeyPath, privateKey, 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if err := ioutil.WriteFile(publicKeyPath, publicKey, 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if err := ioutil.WriteFile(updatePath, []byte("update"), 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+
+		// Missing signature
+		if err := VerifyFileSignature(updatePath, publicKeyPath); err == nil {
+			t.Errorf("Test failed for %s. Expected an error for the missing signature", keyType)
+		}
+
+		signaturePath, err := SignFile(updatePath, privateKeyPath)
+		if err != nil {
+			t.Fatalf("Test failed for %s. Unexpected error %v", keyType, err)
+		}
+		if signaturePath != GetSignaturePath(updatePath) {
+			t.Errorf("Test failed for %s. Expected: %s, Actual: %s", keyType, GetSignaturePath(updatePath), signaturePath)
+		}
+		if err := VerifyFileSignature(updatePath, publicKeyPath); err != nil {
+			t.Errorf("Test failed for %s. Unexpected error %v", keyType, err)
+		}
+
+		// Modified update
+		if err := ioutil.WriteFile(updatePath, []byte("modified update"), 0600); err != nil {
+			t.Fatalf("Test failed. Unexpected error %v", err)
+		}
+		if err := VerifyFileSignature(updatePath, publicKeyPath); err == nil {
+			t.Errorf("Test failed for %s. Expected an error for the modified update", keyType)
+		}
+	}
+}
+
+func TestGenerateKeysWithInvalidType(t *testing.T) {
+	if _, _, err := GenerateKeys("dsa"); err == nil {
+		t.Error("Test failed. Expected an error for an unsupported key type")
+	}
+}
@@ -11,11 +11,14 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -29,6 +32,12 @@ import (
 
 var logger = log.Logger()
 
+// Functions which should be called before the tool exits
+var (
+	cleanupFuncs []func()
+	cleanupLock sync.Mutex
+)
+
 // struct which is used to read update-descriptor.yaml
 type UpdateDescriptor struct {
 	Update_number    string
@@ -37,6 +46,8 @@ type UpdateDescriptor struct {
 	Applies_to       string
 	Bug_fixes        map[string]string
 	Description      string
+	Requires         []string `yaml:",omitempty"`
+	Supersedes       []string `yaml:",omitempty"`
 	File_changes     struct {
 				 Added_files    []string
 				 Removed_files  []string
@@ -91,20 +102,42 @@ func CleanUpDirectory(path string) {
 	}
 }
 
-// This function handles keyboard interrupts
+// This function handles keyboard interrupts. The given cleanup function is also registered using RegisterCleanup so
+// that it will be called if the tool exits due to an error.
 func HandleInterrupts(cleanupFunc func()) chan <- os.Signal {
+	RegisterCleanup(cleanupFunc)
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
 	signal.Notify(c, syscall.SIGTERM)
 	go func() {
 		<-c
 		PrintInfo("Keyboard interrupt received.")
-		cleanupFunc()
+		RunCleanup()
 		os.Exit(1)
 	}()
 	return c
 }
 
+// This function will register a function which should be called before the tool exits. Registered functions are
+// called by RunCleanup.
+func RegisterCleanup(cleanupFunc func()) {
+	cleanupLock.Lock()
+	defer cleanupLock.Unlock()
+	cleanupFuncs = append(cleanupFuncs, cleanupFunc)
+}
+
+// This function will call all registered cleanup functions in the reverse order of registration. Each function is
+// called only once.
+func RunCleanup() {
+	cleanupLock.Lock()
+	registeredFuncs := cleanupFuncs
+	cleanupFuncs = nil
+	cleanupLock.Unlock()
+	for i := len(registeredFuncs) - 1; i >= 0; i-- {
+		registeredFuncs[i]()
+	}
+}
+
 // This function will create all directories in the given path if they do not exist
 func CreateDirectory(path string) error {
 	return os.MkdirAll(path, 0700)
@@ -125,6 +158,54 @@ func GetUserInput() (string, error) {
 	return strings.TrimSpace(preference), nil
 }
 
+// This function will return the command and the arguments of the given editor. The default editor is returned if the
+// given editor is empty or only has whitespace.
+func getEditorCommand(editor string) []string {
+	editorArgs := strings.Fields(editor)
+	if len(editorArgs) > 0 {
+		return editorArgs
+	}
+	if runtime.GOOS == "windows" {
+		return []string{constant.DEFAULT_EDITOR_WINDOWS}
+	}
+	return []string{constant.DEFAULT_EDITOR}
+}
+
+// This function will open the given content in the editor set in the EDITOR environment variable and return the
+// content after the user closes the editor. If EDITOR is not set, a default editor will be used.
+func GetUserInputFromEditor(content string) (string, error) {
+	// EDITOR might contain arguments as well. Ex: 'code --wait'
+	editorArgs := getEditorCommand(os.Getenv(constant.EDITOR_ENV_VARIABLE))
+	logger.Debug(fmt.Sprintf("editor: %s", editorArgs))
+
+	// Write the content to a temporary file which will be opened in the editor
+	file, err := ioutil.TempFile("", "wum-uc-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.WriteString(content)
+	file.Close()
+	if err != nil {
+		return "", err
+	}
+
+	command := exec.Command(editorArgs[0], append(editorArgs[1:], file.Name())...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	err = command.Run()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
 // This function will process user input and identify the type of preference
 func ProcessUserPreference(preference string) int {
 	if strings.ToLower(preference) == "yes" || (len(preference) == 1 && strings.ToLower(preference) == "y" ) {
@@ -186,36 +267,88 @@ func LoadUpdateDescriptor(filename, updateDirectoryPath string) (*UpdateDescript
 
 // This function will validate the update-descriptor.yaml
 func ValidateUpdateDescriptor(updateDescriptor *UpdateDescriptor) error {
-	if len(updateDescriptor.Update_number) == 0 {
+	if err := ValidateUpdateNumber(updateDescriptor.Update_number); err != nil {
+		return err
+	}
+	if err := ValidatePlatformVersion(updateDescriptor.Platform_version); err != nil {
+		return err
+	}
+	if err := ValidatePlatformName(updateDescriptor.Platform_name); err != nil {
+		return err
+	}
+	if err := ValidateAppliesTo(updateDescriptor.Applies_to); err != nil {
+		return err
+	}
+	if err := ValidateBugFixes(updateDescriptor.Bug_fixes); err != nil {
+		return err
+	}
+	if err := ValidateDescription(updateDescriptor.Description); err != nil {
+		return err
+	}
+	if err := ValidateUpdateDependencies(updateDescriptor.Update_number, updateDescriptor.Requires,
+		updateDescriptor.Supersedes); err != nil {
+		return err
+	}
+	return nil
+}
+
+// This function will validate the update_number field of the update-descriptor.yaml
+func ValidateUpdateNumber(updateNumber string) error {
+	if len(updateNumber) == 0 {
 		return errors.New("'update_number' field not found.")
 	}
-	matches, err := regexp.MatchString(constant.UPDATE_NUMBER_REGEX, updateDescriptor.Update_number)
+	matches, err := regexp.MatchString(constant.UPDATE_NUMBER_REGEX, updateNumber)
 	if err != nil {
 		return err
 	}
 	if !matches {
 		return errors.New(fmt.Sprintf("'update_number' is not valid. It should match '%s'.", constant.UPDATE_NUMBER_REGEX))
 	}
-	if len(updateDescriptor.Platform_version) == 0 {
+	return nil
+}
+
+// This function will validate the platform_version field of the update-descriptor.yaml
+func ValidatePlatformVersion(platformVersion string) error {
+	if len(platformVersion) == 0 {
 		return errors.New("'platform_version' field not found.")
 	}
-	matches, err = regexp.MatchString(constant.KERNEL_VERSION_REGEX, updateDescriptor.Platform_version)
+	matches, err := regexp.MatchString(constant.KERNEL_VERSION_REGEX, platformVersion)
 	if err != nil {
 		return err
 	}
 	if !matches {
 		return errors.New(fmt.Sprintf("'platform_version' is not valid. It should match '%s'.", constant.KERNEL_VERSION_REGEX))
 	}
-	if len(updateDescriptor.Platform_name) == 0 {
+	return nil
+}
+
+// This function will validate the platform_name field of the update-descriptor.yaml
+func ValidatePlatformName(platformName string) error {
+	if len(platformName) == 0 {
 		return errors.New("'platform_name' field not found.")
 	}
-	if len(updateDescriptor.Applies_to) == 0 {
+	return nil
+}
+
+// This function will validate the applies_to field of the update-descriptor.yaml
+func ValidateAppliesTo(appliesTo string) error {
+	if len(appliesTo) == 0 {
 		return errors.New("'applies_to' field not found.")
 	}
-	if len(updateDescriptor.Bug_fixes) == 0 {
+	return nil
+}
+
+// This function will validate the bug_fixes field of the update-descriptor.yaml
+func ValidateBugFixes(bugFixes map[string]string) error {
+	if len(bugFixes) == 0 {
 		return errors.New("'bug_fixes' field not found. Add 'N/A: N/A' if there are no bug fixes.")
 	}
-	if len(updateDescriptor.Description) == 0 {
+	return nil
+}
+
+// This function will validate the description field of the update-descriptor.yaml
+func ValidateDescription(description string) error {
+	if len(description) == 0 {
 		return errors.New("'description' field not found.")
 	}
 	return nil
@@ -342,6 +475,7 @@ func HandleErrorAndExit(err error, customMessage ...interface{}) {
 		} else {
 			PrintError(append(customMessage, err.Error())...)
 		}
+		RunCleanup()
 		os.Exit(1)
 	}
 }
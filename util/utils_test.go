@@ -199,6 +199,45 @@ func TestValidateUpdateDescriptor(t *testing.T) {
 	}
 }
 
+func TestValidateUpdateNumber(t *testing.T) {
+	err := ValidateUpdateNumber("")
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidateUpdateNumber("001")
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidateUpdateNumber("ADD_UPDATE_NUMBER_HERE")
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidateUpdateNumber("0001")
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+}
+
+func TestValidatePlatformVersion(t *testing.T) {
+	err := ValidatePlatformVersion("")
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidatePlatformVersion("4.4")
+	if err == nil {
+		t.Error("Test failed. Error expected")
+	}
+
+	err = ValidatePlatformVersion("4.4.0")
+	if err != nil {
+		t.Errorf("Test failed. Unexpected error %v", err)
+	}
+}
+
 func TestIsStringIsInSlice(t *testing.T) {
 	data := []string{"a", "b", "c"}
 	str := "a"
@@ -325,3 +364,18 @@ func TestProcessString02(t *testing.T) {
 		t.Errorf("Test failed, expected: '%v', actual: '%v'", expectedResult, result)
 	}
 }
+
+func TestGetEditorCommand(t *testing.T) {
+	editorArgs := getEditorCommand("code --wait")
+	if len(editorArgs) != 2 || editorArgs[0] != "code" || editorArgs[1] != "--wait" {
+		t.Errorf("Test failed, expected: %v, actual: %v", []string{"code", "--wait"}, editorArgs)
+	}
+
+	// Editor with only whitespace should not be used
+	for _, editor := range []string{"", "   "} {
+		editorArgs = getEditorCommand(editor)
+		if len(editorArgs) != 1 || (editorArgs[0] != constant.DEFAULT_EDITOR && editorArgs[0] != constant.DEFAULT_EDITOR_WINDOWS) {
+			t.Errorf("Test failed, expected: %s, actual: %v", constant.DEFAULT_EDITOR, editorArgs)
+		}
+	}
+}